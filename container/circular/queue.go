@@ -0,0 +1,231 @@
+package circular
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	lctx "github.com/leoheung/go-patterns/container/context"
+	"github.com/leoheung/go-patterns/container/safeslice"
+	"github.com/leoheung/go-patterns/utils"
+)
+
+// FullPolicy 决定 CircularQueue 满时 Enqueue 的行为。
+type FullPolicy int
+
+const (
+	// Overwrite 覆盖最旧的元素,Enqueue 总是成功
+	Overwrite FullPolicy = iota
+	// DropNewest 丢弃新元素,Enqueue 返回 ErrQueueFull
+	DropNewest
+	// Block 阻塞直到有空位、ctx 结束或队列被暂停
+	Block
+)
+
+var ErrQueueFull = fmt.Errorf("CircularQueue is full")
+
+// CircularQueue is the fixed-capacity, first-in-first-out counterpart of
+// CircularStack, intended for buffering telemetry between a producer and a
+// consumer that may fall behind.
+//
+// What happens when the buffer is full is decided by its FullPolicy: overwrite
+// the oldest item, drop the new item, or block the producer until the consumer
+// frees a slot. Subscribe, Pause and Resume behave exactly as on CircularStack:
+// subscribers are notified after every successful Enqueue, pausing stops
+// notifications and rejects Enqueue/Dequeue, and resuming restarts them.
+type CircularQueue[T any] struct {
+	subscribers   *safeslice.SafeSlice[chan struct{}]
+	buf           []T
+	ctx           *lctx.RenewableContext[any]
+	mu            sync.Mutex
+	policy        FullPolicy
+	head          int // 最旧元素的下标
+	count         int
+	dropped       uint64
+	waiters       int
+	space_ch      chan struct{} // 出队后关闭并替换,唤醒阻塞的 Enqueue
+	notify_buffer chan struct{}
+}
+
+func NewCircularQueue[T any](size int, policy FullPolicy) *CircularQueue[T] {
+	if size <= 0 {
+		size = 1
+	}
+
+	ret := &CircularQueue[T]{
+		subscribers:   safeslice.NewSafeSlice[chan struct{}](0, 0),
+		ctx:           lctx.NewRenewableContext[any](nil, nil),
+		buf:           make([]T, size),
+		policy:        policy,
+		space_ch:      make(chan struct{}),
+		notify_buffer: make(chan struct{}, 1),
+	}
+
+	go ret.notify()
+
+	return ret
+}
+
+func (cq *CircularQueue[T]) notify() {
+	for {
+		select {
+		case <-cq.ctx.Done():
+			return
+
+		case <-cq.notify_buffer:
+			cq.subscribers.Range(func(index int, item chan struct{}) bool {
+				utils.TryEnqueue(item, struct{}{})
+				return true
+			})
+		}
+	}
+}
+
+// Enqueue 入队;Block 策略下一直阻塞到有空位或队列被暂停
+func (cq *CircularQueue[T]) Enqueue(data T) error {
+	return cq.EnqueueContext(context.Background(), data)
+}
+
+// EnqueueContext 入队;Block 策略下 ctx 结束时返回 ctx.Err()
+func (cq *CircularQueue[T]) EnqueueContext(ctx context.Context, data T) error {
+	for {
+		cq.mu.Lock()
+		if !cq.ctx.IsAlive() {
+			cq.mu.Unlock()
+			return fmt.Errorf("CircularQueue is paused")
+		}
+
+		if cq.count < len(cq.buf) {
+			cq.buf[(cq.head+cq.count)%len(cq.buf)] = data
+			cq.count++
+			cq.mu.Unlock()
+			utils.TryEnqueue(cq.notify_buffer, struct{}{})
+			return nil
+		}
+
+		switch cq.policy {
+		case Overwrite:
+			cq.buf[cq.head] = data
+			cq.head = (cq.head + 1) % len(cq.buf)
+			cq.dropped++
+			cq.mu.Unlock()
+			utils.TryEnqueue(cq.notify_buffer, struct{}{})
+			return nil
+		case DropNewest:
+			cq.dropped++
+			cq.mu.Unlock()
+			return ErrQueueFull
+		}
+
+		space, paused := cq.space_ch, cq.ctx.Done()
+		cq.waiters++
+		cq.mu.Unlock()
+
+		select {
+		case <-space:
+		case <-paused:
+		case <-ctx.Done():
+			cq.mu.Lock()
+			cq.waiters--
+			cq.mu.Unlock()
+			return ctx.Err()
+		}
+
+		cq.mu.Lock()
+		cq.waiters--
+		cq.mu.Unlock()
+	}
+}
+
+// Peek 查看最旧的元素
+func (cq *CircularQueue[T]) Peek() (T, error) {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+
+	var zero T
+	if cq.count == 0 {
+		return zero, fmt.Errorf("CircularQueue is empty")
+	}
+
+	return cq.buf[cq.head], nil
+}
+
+// Dequeue 取出最旧的元素
+func (cq *CircularQueue[T]) Dequeue() (T, error) {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+
+	var zero T
+	if !cq.ctx.IsAlive() {
+		return zero, fmt.Errorf("CircularQueue is paused")
+	}
+
+	if cq.count == 0 {
+		return zero, fmt.Errorf("CircularQueue is empty")
+	}
+
+	ret := cq.buf[cq.head]
+	cq.buf[cq.head] = zero
+	cq.head = (cq.head + 1) % len(cq.buf)
+	cq.count--
+
+	if cq.waiters > 0 {
+		close(cq.space_ch)
+		cq.space_ch = make(chan struct{})
+	}
+	return ret, nil
+}
+
+// Len 当前元素个数
+func (cq *CircularQueue[T]) Len() int {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	return cq.count
+}
+
+// Cap 容量
+func (cq *CircularQueue[T]) Cap() int {
+	return len(cq.buf)
+}
+
+// Dropped 返回因队列已满而被覆盖(Overwrite)或丢弃(DropNewest)的元素总数
+func (cq *CircularQueue[T]) Dropped() uint64 {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	return cq.dropped
+}
+
+func (cq *CircularQueue[T]) Pause() {
+	cq.ctx.Cancel()
+}
+
+func (cq *CircularQueue[T]) Resume() error {
+	err := cq.ctx.Reactivate(nil)
+	if err != nil {
+		return err
+	}
+
+	go cq.notify()
+	return nil
+}
+
+func (cq *CircularQueue[T]) Subscribe(buffer int) (<-chan struct{}, func(), error) {
+	if !cq.ctx.IsAlive() {
+		return nil, nil, fmt.Errorf("CircularQueue is paused")
+	}
+
+	if buffer < 0 {
+		buffer = 0
+	}
+	ch := make(chan struct{}, buffer)
+	cq.subscribers.Append(ch)
+
+	unsubscribe := func() {
+		removed_count := cq.subscribers.RemoveIf(func(c chan struct{}) bool { return c == ch })
+		if removed_count == 1 {
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe, nil
+}
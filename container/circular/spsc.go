@@ -0,0 +1,86 @@
+package circular
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// SPSCRing 是无锁的单生产者 / 单消费者环形缓冲区,容量为 2 的幂,下标用位与取模。
+//
+// 只允许一个 goroutine 调用 TryEnqueue、一个 goroutine 调用 TryDequeue(可以是不同的 goroutine);
+// 违反该约定会破坏数据,且不会被检测到。Len / Cap 可在任意 goroutine 调用。
+// 队列满时 TryEnqueue 直接返回 false,需要覆盖或阻塞语义时请使用 CircularQueue。
+type SPSCRing[T any] struct {
+	buf  []T
+	mask uint64
+
+	_    [64]byte
+	head atomic.Uint64 // 下一个读位置,只由消费者写
+	_    [56]byte
+	tail atomic.Uint64 // 下一个写位置,只由生产者写
+	_    [56]byte
+
+	// 各自缓存对方的位置,减少跨核读取
+	cachedHead uint64 // 生产者持有
+	_          [56]byte
+	cachedTail uint64 // 消费者持有
+	_          [56]byte
+}
+
+// NewSPSCRing 创建容量为 capacity 向上取整到 2 的幂的 SPSCRing;capacity <= 0 时取 1。
+func NewSPSCRing[T any](capacity int) *SPSCRing[T] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	size := uint64(1) << bits.Len64(uint64(capacity-1))
+	return &SPSCRing[T]{
+		buf:  make([]T, size),
+		mask: size - 1,
+	}
+}
+
+// TryEnqueue 写入 v,队列满时返回 false。只能由生产者调用。
+func (r *SPSCRing[T]) TryEnqueue(v T) bool {
+	tail := r.tail.Load()
+	if tail-r.cachedHead == uint64(len(r.buf)) {
+		r.cachedHead = r.head.Load()
+		if tail-r.cachedHead == uint64(len(r.buf)) {
+			return false
+		}
+	}
+	r.buf[tail&r.mask] = v
+	r.tail.Store(tail + 1)
+	return true
+}
+
+// TryDequeue 取出最旧的元素,队列空时返回 false。只能由消费者调用。
+func (r *SPSCRing[T]) TryDequeue() (T, bool) {
+	var zero T
+	head := r.head.Load()
+	if head == r.cachedTail {
+		r.cachedTail = r.tail.Load()
+		if head == r.cachedTail {
+			return zero, false
+		}
+	}
+	v := r.buf[head&r.mask]
+	r.buf[head&r.mask] = zero
+	r.head.Store(head + 1)
+	return v, true
+}
+
+// Len 返回元素个数;并发读写时只是近似值。
+func (r *SPSCRing[T]) Len() int {
+	head := r.head.Load()
+	tail := r.tail.Load()
+	if tail < head {
+		// 两次读取之间消费者追上了生产者
+		return 0
+	}
+	return int(tail - head)
+}
+
+// Cap 返回容量(2 的幂)。
+func (r *SPSCRing[T]) Cap() int {
+	return len(r.buf)
+}
@@ -0,0 +1,334 @@
+package circular
+
+import (
+	"cmp"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/leoheung/go-patterns/container/tree/bst/treap"
+)
+
+// RollingStats 维护最近 N 个样本和 / 或最近一段时间内样本的滚动统计量,并发安全。
+//
+// 每次 Add 的代价:
+//   - Count / Sum / Mean / Variance:Welford 增量更新,O(1)
+//   - Min / Max:单调双端队列,均摊 O(1)
+//   - Quantile:样本同时保存在允许重复元素的 treap 中,插入 / 淘汰 / 按名次查询均为期望 O(log n),
+//     结果是精确的 nearest-rank 分位数
+//
+// 按时间窗口淘汰发生在 Add 和每次查询时;样本时间戳应单调不减(AddAt 传入更早的时间会被当作当前最新时间)。
+type RollingStats struct {
+	mu     sync.Mutex
+	size   int           // 最多保留的样本数,0 表示不限
+	window time.Duration // 最长保留时间,0 表示不限
+	now    func() time.Time
+
+	samples deque[sample]
+	seq     uint64        // 下一个样本的序号
+	minq    deque[sample] // 值单调递增,队首为最小值
+	maxq    deque[sample] // 值单调递减,队首为最大值
+	sorted  *treap.Treap[float64]
+
+	sum  float64
+	mean float64
+	m2   float64 // 离差平方和
+}
+
+type sample struct {
+	seq   uint64
+	value float64
+	at    time.Time
+}
+
+// Summary 是某一时刻全部统计量的快照。
+type Summary struct {
+	Count    int
+	Sum      float64
+	Mean     float64
+	Min      float64
+	Max      float64
+	Variance float64 // 总体方差
+	StdDev   float64
+}
+
+// NewRollingStats 创建滚动统计:size > 0 时只统计最近 size 个样本,window > 0 时只统计最近 window 内的样本,
+// 两者都设置时同时生效。两者都 <= 0 时 panic。
+func NewRollingStats(size int, window time.Duration) *RollingStats {
+	if size <= 0 && window <= 0 {
+		panic("RollingStats: size or window must be > 0")
+	}
+	return &RollingStats{
+		size:   max(size, 0),
+		window: max(window, 0),
+		now:    time.Now,
+		sorted: treap.NewTreap(cmp.Compare[float64]),
+	}
+}
+
+// Add 以当前时间加入样本
+func (rs *RollingStats) Add(v float64) {
+	rs.AddAt(v, rs.now())
+}
+
+// AddDuration 以秒为单位加入一个耗时样本
+func (rs *RollingStats) AddDuration(d time.Duration) {
+	rs.Add(d.Seconds())
+}
+
+// AddAt 以时间 at 加入样本
+func (rs *RollingStats) AddAt(v float64, at time.Time) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if last, ok := rs.samples.back(); ok && at.Before(last.at) {
+		at = last.at
+	}
+	s := sample{seq: rs.seq, value: v, at: at}
+	rs.seq++
+
+	rs.samples.pushBack(s)
+	rs.sorted.Insert(v)
+	rs.sum += v
+	n := float64(rs.samples.len())
+	delta := v - rs.mean
+	rs.mean += delta / n
+	rs.m2 += delta * (v - rs.mean)
+
+	for b, ok := rs.minq.back(); ok && b.value > v; b, ok = rs.minq.back() {
+		rs.minq.popBack()
+	}
+	rs.minq.pushBack(s)
+	for b, ok := rs.maxq.back(); ok && b.value < v; b, ok = rs.maxq.back() {
+		rs.maxq.popBack()
+	}
+	rs.maxq.pushBack(s)
+
+	if rs.size > 0 && rs.samples.len() > rs.size {
+		rs.evict_oldest()
+	}
+	rs.evict_expired(at)
+}
+
+// evict_expired 淘汰时间窗口之外的样本
+func (rs *RollingStats) evict_expired(now time.Time) {
+	if rs.window <= 0 {
+		return
+	}
+	cutoff := now.Add(-rs.window)
+	for f, ok := rs.samples.front(); ok && !f.at.After(cutoff); f, ok = rs.samples.front() {
+		rs.evict_oldest()
+	}
+}
+
+// evict_oldest 淘汰最旧的样本,逆向执行 Welford 更新
+func (rs *RollingStats) evict_oldest() {
+	s, _ := rs.samples.popFront()
+	rs.sorted.Delete(s.value)
+	rs.sum -= s.value
+
+	if n := rs.samples.len(); n == 0 {
+		rs.sum, rs.mean, rs.m2 = 0, 0, 0
+	} else {
+		oldMean := rs.mean
+		rs.mean -= (s.value - rs.mean) / float64(n)
+		rs.m2 = max(rs.m2-(s.value-oldMean)*(s.value-rs.mean), 0)
+	}
+
+	if f, ok := rs.minq.front(); ok && f.seq == s.seq {
+		rs.minq.popFront()
+	}
+	if f, ok := rs.maxq.front(); ok && f.seq == s.seq {
+		rs.maxq.popFront()
+	}
+}
+
+// lock 加锁并按当前时间淘汰过期样本,供查询方法使用
+func (rs *RollingStats) lock() {
+	rs.mu.Lock()
+	rs.evict_expired(rs.now())
+}
+
+// Count 窗口内的样本数
+func (rs *RollingStats) Count() int {
+	rs.lock()
+	defer rs.mu.Unlock()
+	return rs.samples.len()
+}
+
+// Sum 窗口内样本之和
+func (rs *RollingStats) Sum() float64 {
+	rs.lock()
+	defer rs.mu.Unlock()
+	return rs.sum
+}
+
+// Mean 窗口内样本的均值,没有样本时为 0
+func (rs *RollingStats) Mean() float64 {
+	rs.lock()
+	defer rs.mu.Unlock()
+	return rs.mean
+}
+
+// Min 窗口内的最小值,没有样本时返回 false
+func (rs *RollingStats) Min() (float64, bool) {
+	rs.lock()
+	defer rs.mu.Unlock()
+	f, ok := rs.minq.front()
+	return f.value, ok
+}
+
+// Max 窗口内的最大值,没有样本时返回 false
+func (rs *RollingStats) Max() (float64, bool) {
+	rs.lock()
+	defer rs.mu.Unlock()
+	f, ok := rs.maxq.front()
+	return f.value, ok
+}
+
+// Variance 窗口内样本的总体方差
+func (rs *RollingStats) Variance() float64 {
+	rs.lock()
+	defer rs.mu.Unlock()
+	return rs.variance()
+}
+
+func (rs *RollingStats) variance() float64 {
+	if n := rs.samples.len(); n > 0 {
+		return rs.m2 / float64(n)
+	}
+	return 0
+}
+
+// StdDev 窗口内样本的总体标准差
+func (rs *RollingStats) StdDev() float64 {
+	return math.Sqrt(rs.Variance())
+}
+
+// Quantile 返回 q 分位数(nearest-rank,q 取值 [0, 1],越界时截断),没有样本时返回 false。
+func (rs *RollingStats) Quantile(q float64) (float64, bool) {
+	rs.lock()
+	defer rs.mu.Unlock()
+	return rs.quantile(q)
+}
+
+// Quantiles 在同一时刻一次返回多个分位数,例如 Quantiles(0.5, 0.9, 0.99)
+func (rs *RollingStats) Quantiles(qs ...float64) []float64 {
+	rs.lock()
+	defer rs.mu.Unlock()
+	out := make([]float64, len(qs))
+	for i, q := range qs {
+		out[i], _ = rs.quantile(q)
+	}
+	return out
+}
+
+func (rs *RollingStats) quantile(q float64) (float64, bool) {
+	n := rs.samples.len()
+	if n == 0 {
+		return 0, false
+	}
+	rank := int(math.Ceil(min(max(q, 0), 1)*float64(n))) - 1
+	return rs.sorted.Select(max(rank, 0))
+}
+
+// Summary 在同一时刻读取全部统计量
+func (rs *RollingStats) Summary() Summary {
+	rs.lock()
+	defer rs.mu.Unlock()
+	v := rs.variance()
+	minv, _ := rs.minq.front()
+	maxv, _ := rs.maxq.front()
+	return Summary{
+		Count:    rs.samples.len(),
+		Sum:      rs.sum,
+		Mean:     rs.mean,
+		Min:      minv.value,
+		Max:      maxv.value,
+		Variance: v,
+		StdDev:   math.Sqrt(v),
+	}
+}
+
+// Reset 清空全部样本
+func (rs *RollingStats) Reset() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.samples = deque[sample]{}
+	rs.minq = deque[sample]{}
+	rs.maxq = deque[sample]{}
+	rs.sorted.Clear()
+	rs.sum, rs.mean, rs.m2 = 0, 0, 0
+}
+
+// ═══════════════════════════════════════════════════════
+// deque:按需扩容的环形双端队列
+// ═══════════════════════════════════════════════════════
+
+type deque[T any] struct {
+	buf   []T
+	head  int
+	count int
+}
+
+func (d *deque[T]) len() int {
+	return d.count
+}
+
+func (d *deque[T]) pushBack(v T) {
+	if d.count == len(d.buf) {
+		d.grow()
+	}
+	d.buf[(d.head+d.count)%len(d.buf)] = v
+	d.count++
+}
+
+func (d *deque[T]) popFront() (T, bool) {
+	var zero T
+	if d.count == 0 {
+		return zero, false
+	}
+	v := d.buf[d.head]
+	d.buf[d.head] = zero
+	d.head = (d.head + 1) % len(d.buf)
+	d.count--
+	return v, true
+}
+
+func (d *deque[T]) popBack() (T, bool) {
+	var zero T
+	if d.count == 0 {
+		return zero, false
+	}
+	i := (d.head + d.count - 1) % len(d.buf)
+	v := d.buf[i]
+	d.buf[i] = zero
+	d.count--
+	return v, true
+}
+
+func (d *deque[T]) front() (T, bool) {
+	if d.count == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.head], true
+}
+
+func (d *deque[T]) back() (T, bool) {
+	if d.count == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[(d.head+d.count-1)%len(d.buf)], true
+}
+
+// grow 容量翻倍,并把元素重新排到从 0 开始
+func (d *deque[T]) grow() {
+	buf := make([]T, max(2*len(d.buf), 8))
+	for i := range d.count {
+		buf[i] = d.buf[(d.head+i)%len(d.buf)]
+	}
+	d.buf = buf
+	d.head = 0
+}
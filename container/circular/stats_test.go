@@ -0,0 +1,146 @@
+package circular
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// stampedValue 是暴力模型中的一个样本
+type stampedValue struct {
+	v  float64
+	at time.Time
+}
+
+var testQuantiles = []float64{0, 0.1, 0.25, 0.5, 0.9, 0.99, 1}
+
+// checkAgainstWindow 用暴力方法重新计算 window 的各项统计量并与 rs 比对
+func checkAgainstWindow(t *testing.T, step int, rs *RollingStats, window []stampedValue) {
+	t.Helper()
+	if got := rs.Count(); got != len(window) {
+		t.Fatalf("step %d: Count = %d, want %d", step, got, len(window))
+	}
+	if len(window) == 0 {
+		if _, ok := rs.Min(); ok {
+			t.Fatalf("step %d: Min on empty window: ok = true", step)
+		}
+		if _, ok := rs.Quantile(0.5); ok {
+			t.Fatalf("step %d: Quantile on empty window: ok = true", step)
+		}
+		return
+	}
+
+	vals := make([]float64, len(window))
+	sum := 0.0
+	for i, s := range window {
+		vals[i] = s.v
+		sum += s.v
+	}
+	mean := sum / float64(len(vals))
+	sq := 0.0
+	for _, v := range vals {
+		sq += (v - mean) * (v - mean)
+	}
+	variance := sq / float64(len(vals))
+	slices.Sort(vals)
+
+	near := func(got, want float64) bool {
+		return math.Abs(got-want) <= 1e-6*max(1, math.Abs(want))
+	}
+	sm := rs.Summary()
+	if !near(sm.Sum, sum) || !near(sm.Mean, mean) || !near(sm.Variance, variance) || !near(sm.StdDev, math.Sqrt(variance)) {
+		t.Fatalf("step %d: Summary = %+v, want sum %v mean %v variance %v", step, sm, sum, mean, variance)
+	}
+	if sm.Min != vals[0] || sm.Max != vals[len(vals)-1] {
+		t.Fatalf("step %d: Min/Max = %v/%v, want %v/%v", step, sm.Min, sm.Max, vals[0], vals[len(vals)-1])
+	}
+	if v, ok := rs.Min(); !ok || v != vals[0] {
+		t.Fatalf("step %d: Min = %v, %v, want %v", step, v, ok, vals[0])
+	}
+	if v, ok := rs.Max(); !ok || v != vals[len(vals)-1] {
+		t.Fatalf("step %d: Max = %v, %v, want %v", step, v, ok, vals[len(vals)-1])
+	}
+	got := rs.Quantiles(testQuantiles...)
+	for i, q := range testQuantiles {
+		rank := max(int(math.Ceil(q*float64(len(vals))))-1, 0)
+		if got[i] != vals[rank] {
+			t.Fatalf("step %d: Quantile(%v) = %v, want %v", step, q, got[i], vals[rank])
+		}
+	}
+}
+
+// TestRollingStatsSizeWindow 按样本数淘汰,值域很小以产生大量重复值
+func TestRollingStatsSizeWindow(t *testing.T) {
+	const size = 50
+	rs := NewRollingStats(size, 0)
+	var window []stampedValue
+	for step := range 3000 {
+		v := float64(rand.IntN(20) - 10)
+		rs.Add(v)
+		window = append(window, stampedValue{v: v})
+		if len(window) > size {
+			window = window[1:]
+		}
+		checkAgainstWindow(t, step, rs, window)
+	}
+
+	rs.Reset()
+	checkAgainstWindow(t, -1, rs, nil)
+	rs.Add(3)
+	checkAgainstWindow(t, -1, rs, []stampedValue{{v: 3}})
+}
+
+// TestRollingStatsTimeWindow 通过 rs.now 注入时钟,按时间淘汰:Add 与查询时都会淘汰过期样本
+func TestRollingStatsTimeWindow(t *testing.T) {
+	const window = 10 * time.Second
+	for _, size := range []int{0, 8} {
+		rs := NewRollingStats(size, window)
+		cur := time.Unix(1_000_000, 0)
+		rs.now = func() time.Time { return cur }
+
+		var model []stampedValue
+		evict := func() {
+			cutoff := cur.Add(-window)
+			for len(model) > 0 && !model[0].at.After(cutoff) {
+				model = model[1:]
+			}
+		}
+		for step := range 3000 {
+			cur = cur.Add(time.Duration(rand.IntN(1500)) * time.Millisecond)
+			if rand.IntN(4) > 0 {
+				v := float64(rand.IntN(10))
+				rs.Add(v)
+				model = append(model, stampedValue{v: v, at: cur})
+				if size > 0 && len(model) > size {
+					model = model[1:]
+				}
+			}
+			evict()
+			checkAgainstWindow(t, step, rs, model)
+		}
+
+		// 只推进时钟、不再写入:查询时同样淘汰
+		cur = cur.Add(window)
+		evict()
+		checkAgainstWindow(t, -1, rs, model)
+	}
+}
+
+// TestRollingStatsAddAtClamp 早于最新样本的时间戳按最新时间处理
+func TestRollingStatsAddAtClamp(t *testing.T) {
+	rs := NewRollingStats(0, time.Minute)
+	cur := time.Unix(1_000_000, 0)
+	rs.now = func() time.Time { return cur }
+
+	rs.AddAt(1, cur)
+	rs.AddAt(2, cur.Add(-time.Hour)) // 会被当作 cur
+	if rs.Count() != 2 {
+		t.Fatalf("Count = %d, want 2", rs.Count())
+	}
+	cur = cur.Add(time.Minute)
+	if rs.Count() != 0 {
+		t.Fatalf("Count = %d after the window passed, want 0", rs.Count())
+	}
+}
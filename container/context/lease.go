@@ -0,0 +1,304 @@
+package context
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/leoheung/go-patterns/container/tree/heap"
+	"github.com/leoheung/go-patterns/utils"
+)
+
+// LeaseID 是租约在所属 LeaseManager 内的唯一编号,从 1 开始递增。
+type LeaseID int64
+
+// Lease 是一份带 TTL 的租约。持有者通过 KeepAlive 续期;到期未续期或被 Revoke 后,
+// 租约结束,它的 Context 被取消。结束的租约不能复活,需要重新 Grant。
+type Lease[T any] struct {
+	id  LeaseID
+	ttl time.Duration
+	ctx *RenewableContext[T]
+	m   *LeaseManager[T]
+
+	// 以下字段由 m.mu 保护
+	deadline time.Time
+	ended    bool
+}
+
+// LeaseInfo 是 List 返回的租约快照。
+type LeaseInfo[T any] struct {
+	ID        LeaseID
+	Holder    T
+	TTL       time.Duration
+	Remaining time.Duration
+}
+
+// ID 返回租约编号
+func (l *Lease[T]) ID() LeaseID {
+	return l.id
+}
+
+// TTL 返回 Grant 时指定的存活时间,每次 KeepAlive 都把截止时间重置为当前时间 + TTL
+func (l *Lease[T]) TTL() time.Duration {
+	return l.ttl
+}
+
+// Holder 返回 Grant 时登记的持有者信息
+func (l *Lease[T]) Holder() T {
+	return l.ctx.GetData()
+}
+
+// Context 返回与租约同生命周期的 context:租约结束时被取消。
+// 截止时间由 LeaseManager 维护,不体现在 Deadline() 中;请勿对它调用 Reactivate。
+func (l *Lease[T]) Context() *RenewableContext[T] {
+	return l.ctx
+}
+
+// Done 等价于 l.Context().Done()
+func (l *Lease[T]) Done() <-chan struct{} {
+	return l.ctx.Done()
+}
+
+// Remaining 返回剩余存活时间;租约已结束时 ok 为 false。
+func (l *Lease[T]) Remaining() (remaining time.Duration, ok bool) {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+	return l.remaining_locked(time.Now())
+}
+
+func (l *Lease[T]) remaining_locked(now time.Time) (time.Duration, bool) {
+	if l.ended {
+		return 0, false
+	}
+	return max(l.deadline.Sub(now), 0), true
+}
+
+// ═══════════════════════════════════════════════════════
+// LeaseManager
+// ═══════════════════════════════════════════════════════
+
+// LeaseManager 管理一组租约,全部租约共用一个最小堆和一个定时器 goroutine:
+// 定时器总是只等待堆顶(最早到期)的租约,数千份租约也只有一个 goroutine 和一个 timer。
+//
+// KeepAlive 只修改租约的截止时间,不调整堆:堆中的条目到期时若发现租约已被续期,
+// 就按新的截止时间重新入堆(惰性续期),因此 KeepAlive 是 O(1),每份租约在堆中只有一个条目。
+// 被 Revoke 的租约的条目留在堆中,到期时丢弃。
+type LeaseManager[T any] struct {
+	mu       sync.Mutex
+	leases   map[LeaseID]*Lease[T]
+	pq       *heap.BinaryHeap[leaseEntry[T]]
+	nextID   LeaseID
+	onExpire atomic.Pointer[func(l *Lease[T])]
+	wake_ch  chan struct{}
+	quit_ch  chan struct{}
+	once     sync.Once
+}
+
+// leaseEntry 是堆中的条目,at 是入堆时租约的截止时间
+type leaseEntry[T any] struct {
+	lease *Lease[T]
+	at    time.Time
+}
+
+// NewLeaseManager 创建 LeaseManager 并启动定时器 goroutine;不再使用时调用 Close。
+func NewLeaseManager[T any]() *LeaseManager[T] {
+	m := &LeaseManager[T]{
+		leases: make(map[LeaseID]*Lease[T]),
+		pq: heap.NewBinaryHeap(func(a, b leaseEntry[T]) bool {
+			return a.at.Before(b.at)
+		}),
+		wake_ch: make(chan struct{}, 1),
+		quit_ch: make(chan struct{}),
+	}
+	go m.cron_expire()
+	return m
+}
+
+// OnExpire 设置过期回调,租约因到期未续期而结束时调用;Revoke 和 Close 不会触发。
+// 回调在锁外、定时器 goroutine 中执行,可以安全地访问本 manager,但不宜长时间阻塞。传 nil 取消回调。
+func (m *LeaseManager[T]) OnExpire(fn func(l *Lease[T])) {
+	if fn == nil {
+		m.onExpire.Store(nil)
+		return
+	}
+	m.onExpire.Store(&fn)
+}
+
+// Grant 为 holder 创建一份 ttl 后到期的租约。
+func (m *LeaseManager[T]) Grant(ttl time.Duration, holder T) (*Lease[T], error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("failed to grant lease: ttl must be > 0, got %v", ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-m.quit_ch:
+		return nil, fmt.Errorf("failed to grant lease: lease manager is closed")
+	default:
+	}
+
+	m.nextID++
+	l := &Lease[T]{
+		id:       m.nextID,
+		ttl:      ttl,
+		ctx:      NewRenewableContext[T](nil, holder),
+		m:        m,
+		deadline: time.Now().Add(ttl),
+	}
+	m.leases[l.id] = l
+	m.pq.Push(leaseEntry[T]{lease: l, at: l.deadline})
+	if head, _ := m.pq.Peek(); head.lease == l {
+		// 新租约成为最早到期者,让定时器重新等待
+		utils.TryEnqueue(m.wake_ch, struct{}{})
+	}
+	return l, nil
+}
+
+// KeepAlive 把租约的截止时间重置为当前时间 + TTL,不会取消或替换它的 Context。
+// 租约已到期、已被 Revoke 或不属于本 manager 时返回错误。
+func (m *LeaseManager[T]) KeepAlive(l *Lease[T]) error {
+	if l.m != m {
+		return fmt.Errorf("failed to keep alive lease %d: lease belongs to another manager", l.id)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if l.ended || !now.Before(l.deadline) {
+		return fmt.Errorf("failed to keep alive lease %d: lease has ended", l.id)
+	}
+	l.deadline = now.Add(l.ttl)
+	return nil
+}
+
+// Revoke 立即结束租约并取消它的 Context,不触发 OnExpire。租约已结束时返回错误。
+func (m *LeaseManager[T]) Revoke(l *Lease[T]) error {
+	if l.m != m {
+		return fmt.Errorf("failed to revoke lease %d: lease belongs to another manager", l.id)
+	}
+
+	m.mu.Lock()
+	if l.ended {
+		m.mu.Unlock()
+		return fmt.Errorf("failed to revoke lease %d: lease has ended", l.id)
+	}
+	l.ended = true
+	delete(m.leases, l.id)
+	m.mu.Unlock()
+
+	l.ctx.Cancel()
+	return nil
+}
+
+// Lookup 按编号查找仍然有效的租约
+func (m *LeaseManager[T]) Lookup(id LeaseID) (*Lease[T], bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.leases[id]
+	return l, ok
+}
+
+// Len 返回有效租约数
+func (m *LeaseManager[T]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.leases)
+}
+
+// List 返回全部有效租约的持有者和剩余存活时间,按编号排序。
+func (m *LeaseManager[T]) List() []LeaseInfo[T] {
+	m.mu.Lock()
+	now := time.Now()
+	out := make([]LeaseInfo[T], 0, len(m.leases))
+	for _, l := range m.leases {
+		remaining, _ := l.remaining_locked(now)
+		out = append(out, LeaseInfo[T]{ID: l.id, Holder: l.ctx.GetData(), TTL: l.ttl, Remaining: remaining})
+	}
+	m.mu.Unlock()
+
+	slices.SortFunc(out, func(a, b LeaseInfo[T]) int { return cmp.Compare(a.ID, b.ID) })
+	return out
+}
+
+// Close 停止定时器 goroutine 并撤销全部租约(取消各自的 Context,不触发 OnExpire)。可重复调用。
+func (m *LeaseManager[T]) Close() {
+	m.once.Do(func() {
+		m.mu.Lock()
+		close(m.quit_ch)
+		leases := make([]*Lease[T], 0, len(m.leases))
+		for _, l := range m.leases {
+			l.ended = true
+			leases = append(leases, l)
+		}
+		clear(m.leases)
+		m.mu.Unlock()
+
+		for _, l := range leases {
+			l.ctx.Cancel()
+		}
+	})
+}
+
+// cron_expire 是唯一的定时器 goroutine:处理到期的租约,然后等待下一个堆顶到期、Grant 唤醒或 Close
+func (m *LeaseManager[T]) cron_expire() {
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		if next, ok := m.expire_due(); ok {
+			timer.Reset(time.Until(next))
+		}
+		select {
+		case <-m.quit_ch:
+			return
+		case <-m.wake_ch:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// expire_due 结束所有已到期的租约并触发回调,返回下一个堆顶的到期时间
+func (m *LeaseManager[T]) expire_due() (next time.Time, ok bool) {
+	var expired []*Lease[T]
+
+	m.mu.Lock()
+	now := time.Now()
+	for {
+		e, found := m.pq.Peek()
+		if !found || e.at.After(now) {
+			break
+		}
+		m.pq.Pop()
+		l := e.lease
+		if l.ended {
+			continue
+		}
+		if l.deadline.After(e.at) {
+			// 期间被续期:按新的截止时间重新入堆
+			m.pq.Push(leaseEntry[T]{lease: l, at: l.deadline})
+			continue
+		}
+		l.ended = true
+		delete(m.leases, l.id)
+		expired = append(expired, l)
+	}
+	if e, has := m.pq.Peek(); has {
+		next, ok = e.at, true
+	}
+	m.mu.Unlock()
+
+	fn := m.onExpire.Load()
+	for _, l := range expired {
+		l.ctx.Cancel()
+		if fn != nil {
+			(*fn)(l)
+		}
+	}
+	return next, ok
+}
@@ -0,0 +1,254 @@
+package context
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// expiryRecorder 记录 OnExpire 的调用顺序
+type expiryRecorder struct {
+	mu  sync.Mutex
+	ids []LeaseID
+	ch  chan LeaseID
+}
+
+func recordExpiry(m *LeaseManager[string]) *expiryRecorder {
+	r := &expiryRecorder{ch: make(chan LeaseID, 64)}
+	m.OnExpire(func(l *Lease[string]) {
+		r.mu.Lock()
+		r.ids = append(r.ids, l.ID())
+		r.mu.Unlock()
+		r.ch <- l.ID()
+	})
+	return r
+}
+
+func (r *expiryRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.ids)
+}
+
+// waitExpired 等待下一次 OnExpire
+func (r *expiryRecorder) waitExpired(t *testing.T) LeaseID {
+	t.Helper()
+	select {
+	case id := <-r.ch:
+		return id
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a lease to expire")
+		return 0
+	}
+}
+
+func isDone(l *Lease[string]) bool {
+	select {
+	case <-l.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// TestLeaseExpiryOrder 租约按截止时间先后到期,被 Revoke 的租约不触发 OnExpire
+func TestLeaseExpiryOrder(t *testing.T) {
+	m := NewLeaseManager[string]()
+	defer m.Close()
+	r := recordExpiry(m)
+
+	// 乱序 Grant,到期顺序只取决于截止时间
+	l3, _ := m.Grant(150*time.Millisecond, "c")
+	l1, _ := m.Grant(50*time.Millisecond, "a")
+	revoked, _ := m.Grant(75*time.Millisecond, "r")
+	l2, _ := m.Grant(100*time.Millisecond, "b")
+	if err := m.Revoke(revoked); err != nil {
+		t.Fatal(err)
+	}
+	if m.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", m.Len())
+	}
+
+	for _, want := range []*Lease[string]{l1, l2, l3} {
+		if got := r.waitExpired(t); got != want.ID() {
+			t.Fatalf("expired lease %d, want %d (%s)", got, want.ID(), want.Holder())
+		}
+		if !isDone(want) {
+			t.Fatalf("lease %d context not canceled after expiry", want.ID())
+		}
+	}
+	if m.Len() != 0 {
+		t.Fatalf("Len = %d after all leases expired", m.Len())
+	}
+	if r.count() != 3 {
+		t.Fatalf("OnExpire fired %d times, want 3", r.count())
+	}
+}
+
+// TestLeaseKeepAlive 持续续期的租约不会到期,停止续期后到期
+func TestLeaseKeepAlive(t *testing.T) {
+	m := NewLeaseManager[string]()
+	defer m.Close()
+	r := recordExpiry(m)
+
+	const ttl = 200 * time.Millisecond
+	l, _ := m.Grant(ttl, "worker")
+	ctx := l.Context()
+	for range 10 {
+		time.Sleep(ttl / 5)
+		if err := m.KeepAlive(l); err != nil {
+			t.Fatalf("KeepAlive: %v", err)
+		}
+	}
+	if isDone(l) || r.count() != 0 {
+		t.Fatal("lease expired while being kept alive")
+	}
+	if remaining, ok := l.Remaining(); !ok || remaining > ttl {
+		t.Fatalf("Remaining = %v, %v", remaining, ok)
+	}
+	if l.Context() != ctx {
+		t.Fatal("KeepAlive replaced the lease context")
+	}
+
+	if got := r.waitExpired(t); got != l.ID() {
+		t.Fatalf("expired lease %d, want %d", got, l.ID())
+	}
+	if err := m.KeepAlive(l); err == nil {
+		t.Fatal("KeepAlive after expiry: err = nil")
+	}
+	if _, ok := l.Remaining(); ok {
+		t.Fatal("Remaining after expiry: ok = true")
+	}
+}
+
+// TestLeaseLazyRepush 堆中条目到期时若租约已被续期,按新截止时间重新入堆而不是结束租约
+func TestLeaseLazyRepush(t *testing.T) {
+	m := NewLeaseManager[string]()
+	defer m.Close()
+	r := recordExpiry(m)
+
+	l, _ := m.Grant(time.Hour, "renewed")
+
+	// 构造「续期后旧条目仍在堆中」的状态:条目已到期,截止时间却在一小时后
+	m.mu.Lock()
+	m.pq.Pop()
+	m.pq.Push(leaseEntry[string]{lease: l, at: time.Now().Add(-time.Second)})
+	deadline := l.deadline
+	m.mu.Unlock()
+
+	next, ok := m.expire_due()
+	if !ok || !next.Equal(deadline) {
+		t.Fatalf("next = %v, %v, want the renewed deadline %v", next, ok, deadline)
+	}
+	if isDone(l) || r.count() != 0 {
+		t.Fatal("renewed lease ended by its stale heap entry")
+	}
+	m.mu.Lock()
+	n := m.pq.Len()
+	head, _ := m.pq.Peek()
+	m.mu.Unlock()
+	if n != 1 || head.lease != l || !head.at.Equal(deadline) {
+		t.Fatalf("heap holds %d entries, head at %v, want one entry at %v", n, head.at, deadline)
+	}
+}
+
+// TestLeaseRevoke Revoke 立即取消 Context,不触发 OnExpire,且不能重复 Revoke 或续期
+func TestLeaseRevoke(t *testing.T) {
+	m := NewLeaseManager[string]()
+	defer m.Close()
+	r := recordExpiry(m)
+
+	l, _ := m.Grant(30*time.Millisecond, "x")
+	if got, ok := m.Lookup(l.ID()); !ok || got != l {
+		t.Fatal("Lookup did not find the granted lease")
+	}
+	if err := m.Revoke(l); err != nil {
+		t.Fatal(err)
+	}
+	if !isDone(l) {
+		t.Fatal("context not canceled by Revoke")
+	}
+	if err := m.Revoke(l); err == nil {
+		t.Fatal("second Revoke: err = nil")
+	}
+	if err := m.KeepAlive(l); err == nil {
+		t.Fatal("KeepAlive after Revoke: err = nil")
+	}
+	if _, ok := m.Lookup(l.ID()); ok {
+		t.Fatal("Lookup found a revoked lease")
+	}
+
+	// 让 Revoke 留在堆中的条目到期
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := m.expire_due(); ok {
+		t.Fatal("stale entry of a revoked lease still in the heap")
+	}
+	if r.count() != 0 {
+		t.Fatalf("OnExpire fired %d times for a revoked lease", r.count())
+	}
+
+	other := NewLeaseManager[string]()
+	defer other.Close()
+	foreign, _ := other.Grant(time.Hour, "y")
+	if err := m.KeepAlive(foreign); err == nil {
+		t.Fatal("KeepAlive on a foreign lease: err = nil")
+	}
+	if err := m.Revoke(foreign); err == nil {
+		t.Fatal("Revoke on a foreign lease: err = nil")
+	}
+	if _, err := m.Grant(0, "z"); err == nil {
+		t.Fatal("Grant(0): err = nil")
+	}
+}
+
+// TestLeaseClose Close 撤销全部租约,不触发 OnExpire,之后不能再 Grant
+func TestLeaseClose(t *testing.T) {
+	m := NewLeaseManager[string]()
+	r := recordExpiry(m)
+
+	leases := make([]*Lease[string], 0)
+	for _, ttl := range []time.Duration{20 * time.Millisecond, time.Hour} {
+		l, _ := m.Grant(ttl, "h")
+		leases = append(leases, l)
+	}
+	m.Close()
+	m.Close()
+
+	for _, l := range leases {
+		if !isDone(l) {
+			t.Fatalf("lease %d context not canceled by Close", l.ID())
+		}
+	}
+	if m.Len() != 0 || len(m.List()) != 0 {
+		t.Fatalf("Len = %d after Close", m.Len())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if r.count() != 0 {
+		t.Fatalf("OnExpire fired %d times after Close", r.count())
+	}
+	if _, err := m.Grant(time.Second, "late"); err == nil {
+		t.Fatal("Grant after Close: err = nil")
+	}
+}
+
+// TestLeaseList List 按编号返回有效租约的持有者与剩余时间
+func TestLeaseList(t *testing.T) {
+	m := NewLeaseManager[string]()
+	defer m.Close()
+
+	for _, h := range []string{"a", "b", "c"} {
+		m.Grant(time.Hour, h)
+	}
+	infos := m.List()
+	if len(infos) != 3 {
+		t.Fatalf("List returned %d leases, want 3", len(infos))
+	}
+	for i, info := range infos {
+		if info.ID != LeaseID(i+1) || info.Holder != string(rune('a'+i)) || info.TTL != time.Hour {
+			t.Fatalf("List[%d] = %+v", i, info)
+		}
+		if info.Remaining <= 0 || info.Remaining > time.Hour {
+			t.Fatalf("List[%d].Remaining = %v", i, info.Remaining)
+		}
+	}
+}
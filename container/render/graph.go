@@ -0,0 +1,218 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Graph 是与输出格式无关的有向图描述,容器把自身结构翻译成 Graph,
+// 再由 WriteDOT / WriteMermaid 输出为 Graphviz DOT 或 Mermaid flowchart 文本,
+// 可直接贴进设计文档或交给 dot / mermaid-cli 渲染。
+//
+// 节点与边按添加顺序输出;布局引擎通常会按声明顺序排列兄弟节点,
+// 因此二叉树应先添加左孩子再添加右孩子。
+type Graph struct {
+	Name      string    // 图名,DOT 中作为 digraph 名称;Mermaid 中忽略
+	Direction Direction // 布局方向,默认 TopDown
+	Nodes     []Node
+	Edges     []Edge
+}
+
+// Direction 布局方向。
+type Direction string
+
+const (
+	TopDown   Direction = "TB"
+	LeftRight Direction = "LR"
+)
+
+// Shape 节点形状。
+type Shape string
+
+const (
+	Ellipse Shape = "ellipse" // 默认
+	Circle  Shape = "circle"
+	Box     Shape = "box"
+)
+
+// Node 图中的一个节点。
+type Node struct {
+	ID        string // 图内唯一标识,只能包含字母、数字与下划线
+	Label     string // 显示文本,可包含换行
+	Shape     Shape
+	Fill      string // 填充色,CSS / Graphviz 颜色名或 #rrggbb;空串表示默认
+	FontColor string // 文字颜色;空串表示默认
+	Invisible bool   // 不可见的占位节点,用于固定二叉树中单个孩子的左右位置
+}
+
+// Edge 图中的一条有向边。
+type Edge struct {
+	From      string
+	To        string
+	Label     string
+	Dashed    bool
+	Invisible bool
+}
+
+// NewGraph 创建一张空图。
+func NewGraph(name string, dir Direction) *Graph {
+	return &Graph{Name: name, Direction: dir}
+}
+
+// AddNode 追加节点并返回其下标,调用方可以通过 g.Nodes[i] 继续修改属性。
+func (g *Graph) AddNode(n Node) int {
+	g.Nodes = append(g.Nodes, n)
+	return len(g.Nodes) - 1
+}
+
+// AddEdge 追加一条边。
+func (g *Graph) AddEdge(e Edge) {
+	g.Edges = append(g.Edges, e)
+}
+
+// WriteDOT 以 Graphviz DOT 格式输出。
+func (g *Graph) WriteDOT(out io.Writer) error {
+	var b strings.Builder
+
+	name := g.Name
+	if name == "" {
+		name = "G"
+	}
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(name))
+	fmt.Fprintf(&b, "  rankdir=%s;\n", g.direction())
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	for _, n := range g.Nodes {
+		if n.Invisible {
+			fmt.Fprintf(&b, "  %s [label=\"\", style=invis];\n", n.ID)
+			continue
+		}
+		attrs := []string{"label=" + dotQuote(n.Label)}
+		if n.Shape != "" {
+			attrs = append(attrs, "shape="+string(n.Shape))
+		}
+		if n.Fill != "" {
+			attrs = append(attrs, "style=filled", "fillcolor="+dotQuote(n.Fill))
+		}
+		if n.FontColor != "" {
+			attrs = append(attrs, "fontcolor="+dotQuote(n.FontColor))
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", n.ID, strings.Join(attrs, ", "))
+	}
+
+	for _, e := range g.Edges {
+		var attrs []string
+		if e.Label != "" {
+			attrs = append(attrs, "label="+dotQuote(e.Label))
+		}
+		switch {
+		case e.Invisible:
+			attrs = append(attrs, "style=invis")
+		case e.Dashed:
+			attrs = append(attrs, "style=dashed")
+		}
+		if len(attrs) == 0 {
+			fmt.Fprintf(&b, "  %s -> %s;\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s [%s];\n", e.From, e.To, strings.Join(attrs, ", "))
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// WriteMermaid 以 Mermaid flowchart 格式输出。
+// Mermaid 没有真正的隐藏节点,Invisible 节点被画成无边框的空白节点,Invisible 边用 ~~~ 表示。
+func (g *Graph) WriteMermaid(out io.Writer) error {
+	var b strings.Builder
+
+	dir := g.direction()
+	if dir == TopDown {
+		dir = "TD"
+	}
+	fmt.Fprintf(&b, "flowchart %s\n", dir)
+
+	var styles []string
+	for _, n := range g.Nodes {
+		if n.Invisible {
+			fmt.Fprintf(&b, "  %s[ ]\n", n.ID)
+			styles = append(styles, fmt.Sprintf("  style %s fill:none,stroke:none", n.ID))
+			continue
+		}
+		label := mermaidQuote(n.Label)
+		switch n.Shape {
+		case Circle:
+			fmt.Fprintf(&b, "  %s((%s))\n", n.ID, label)
+		case Box:
+			fmt.Fprintf(&b, "  %s[%s]\n", n.ID, label)
+		default:
+			fmt.Fprintf(&b, "  %s(%s)\n", n.ID, label)
+		}
+
+		var style []string
+		if n.Fill != "" {
+			style = append(style, "fill:"+n.Fill)
+		}
+		if n.FontColor != "" {
+			style = append(style, "color:"+n.FontColor)
+		}
+		if len(style) > 0 {
+			styles = append(styles, fmt.Sprintf("  style %s %s", n.ID, strings.Join(style, ",")))
+		}
+	}
+
+	for _, e := range g.Edges {
+		arrow := "-->"
+		switch {
+		case e.Invisible:
+			arrow = "~~~"
+		case e.Dashed:
+			arrow = "-.->"
+		}
+		if e.Label != "" && !e.Invisible {
+			fmt.Fprintf(&b, "  %s %s|%s| %s\n", e.From, arrow, mermaidQuote(e.Label), e.To)
+		} else {
+			fmt.Fprintf(&b, "  %s %s %s\n", e.From, arrow, e.To)
+		}
+	}
+
+	for _, s := range styles {
+		b.WriteString(s)
+		b.WriteByte('\n')
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func (g *Graph) direction() Direction {
+	if g.Direction == "" {
+		return TopDown
+	}
+	return g.Direction
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
+// dotQuote 把 s 转为 DOT 双引号字符串;换行转为 DOT 的居中换行 \n。
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+var mermaidEscaper = strings.NewReplacer(
+	`"`, "#quot;",
+	"<", "#lt;",
+	">", "#gt;",
+	"|", "#124;",
+	"\r", "",
+	"\n", "<br/>",
+)
+
+// mermaidQuote 把 s 转为 Mermaid 双引号标签;特殊字符改写为 Mermaid 实体,换行转为 <br/>。
+func mermaidQuote(s string) string {
+	return `"` + mermaidEscaper.Replace(s) + `"`
+}
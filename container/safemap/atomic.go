@@ -0,0 +1,79 @@
+package safemap
+
+// 以下操作均在 key 所在分片的写锁内完成,对同一个 key 是原子的,语义与 sync.Map 的同名方法一致。
+
+// LoadAndDelete 删除 key,返回删除前的值以及 key 是否存在。
+func (sm *ShardedMap[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	s := sm.lock(key)
+	defer sm.unlock(s)
+	value, loaded = s.data[key]
+	if loaded {
+		delete(s.data, key)
+	}
+	return value, loaded
+}
+
+// Swap 写入 value,返回之前的值以及 key 之前是否存在。
+func (sm *ShardedMap[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	s := sm.lock(key)
+	defer sm.unlock(s)
+	previous, loaded = s.data[key]
+	s.data[key] = value
+	return previous, loaded
+}
+
+// CompareAndSwap 当 key 的当前值等于 old 时替换为 new,返回是否替换。
+// 用 == 比较;V 的动态类型不可比较时会 panic,此时请使用 CompareAndSwapFunc。
+func (sm *ShardedMap[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	return sm.CompareAndSwapFunc(key, old, new, anyEqual[V])
+}
+
+// CompareAndSwapFunc 同 CompareAndSwap,用 equal 判断当前值与 old 是否相等。
+func (sm *ShardedMap[K, V]) CompareAndSwapFunc(key K, old, new V, equal func(a, b V) bool) (swapped bool) {
+	s := sm.lock(key)
+	defer sm.unlock(s)
+	cur, ok := s.data[key]
+	if !ok || !equal(cur, old) {
+		return false
+	}
+	s.data[key] = new
+	return true
+}
+
+// CompareAndDelete 当 key 的当前值等于 old 时删除 key,返回是否删除。
+// 用 == 比较;V 的动态类型不可比较时会 panic,此时请使用 CompareAndDeleteFunc。
+func (sm *ShardedMap[K, V]) CompareAndDelete(key K, old V) (deleted bool) {
+	return sm.CompareAndDeleteFunc(key, old, anyEqual[V])
+}
+
+// CompareAndDeleteFunc 同 CompareAndDelete,用 equal 判断当前值与 old 是否相等。
+func (sm *ShardedMap[K, V]) CompareAndDeleteFunc(key K, old V, equal func(a, b V) bool) (deleted bool) {
+	s := sm.lock(key)
+	defer sm.unlock(s)
+	cur, ok := s.data[key]
+	if !ok || !equal(cur, old) {
+		return false
+	}
+	delete(s.data, key)
+	return true
+}
+
+// Update 在分片锁内以 fn(旧值, 是否存在) 计算新值:keep 为 true 时写入新值,为 false 时删除 key。
+// 返回最终的值以及 key 在调用后是否存在。fn 内不能再访问本 map 的同一分片,否则会死锁。
+func (sm *ShardedMap[K, V]) Update(key K, fn func(old V, exists bool) (newValue V, keep bool)) (value V, present bool) {
+	s := sm.lock(key)
+	defer sm.unlock(s)
+	old, exists := s.data[key]
+	value, keep := fn(old, exists)
+	if !keep {
+		delete(s.data, key)
+		var zero V
+		return zero, false
+	}
+	s.data[key] = value
+	return value, true
+}
+
+func anyEqual[V any](a, b V) bool {
+	return any(a) == any(b)
+}
@@ -0,0 +1,301 @@
+package safemap
+
+import "iter"
+
+// 跨分片操作的一致性约定:
+//
+//   - Snapshot / Clear 按分片下标顺序同时持有全部分片锁,是原子的:
+//     Snapshot 得到某一时刻的完整映射,Clear 不会与任何单 key 写入交错
+//   - Len / Keys / Values / Range / All 逐个分片加读锁,是「弱一致」的:
+//     每个分片内部反映该分片在某一时刻的状态,但不同分片的时刻不同;
+//     遍历期间并发写入的 key 可能出现也可能不出现,但同一个 key 不会出现两次(迁移期间同样成立)
+//   - SetMany / DeleteMany 按分片分组,每个分片只加一次锁;同一分片内的写入原子可见,
+//     跨分片不保证原子性(读者可能看到一部分分片已写入)
+//
+// Range / All 先在读锁内复制当前分片,再在锁外调用回调,因此回调里可以安全地读写本 map。
+
+// Len 返回键值对总数(弱一致)。
+func (sm *ShardedMap[K, V]) Len() int {
+	return sm.count(func(s *shard[K, V]) int { return len(s.data) })
+}
+
+// Keys 返回全部 key(无序,弱一致)。
+func (sm *ShardedMap[K, V]) Keys() []K {
+	keys := make([]K, 0)
+	for k := range sm.All() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values 返回全部 value(无序,弱一致)。
+func (sm *ShardedMap[K, V]) Values() []V {
+	values := make([]V, 0)
+	for _, v := range sm.All() {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Range 对每个键值对调用 fn,fn 返回 false 时提前停止(无序,弱一致)。
+func (sm *ShardedMap[K, V]) Range(fn func(key K, value V) bool) {
+	for k, v := range sm.All() {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// All 以 iter.Seq2 的形式迭代全部键值对(无序,弱一致)。
+func (sm *ShardedMap[K, V]) All() iter.Seq2[K, V] {
+	return sm.iterate(nil)
+}
+
+// Snapshot 返回某一时刻全部键值对的副本(原子)。
+// 持有全部分片读锁期间写入会被阻塞,map 很大时注意停顿时间。
+func (sm *ShardedMap[K, V]) Snapshot() map[K]V {
+	shards := sm.lockAll(false)
+	defer unlockAll(shards, false)
+
+	n := 0
+	for _, s := range shards {
+		n += len(s.data)
+	}
+	out := make(map[K]V, n)
+	for _, s := range shards {
+		for k, v := range s.data {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Clear 删除全部键值对(原子)。
+func (sm *ShardedMap[K, V]) Clear() {
+	shards := sm.lockAll(true)
+	defer unlockAll(shards, true)
+
+	for _, s := range shards {
+		if !s.moved.Load() {
+			clear(s.data)
+			s.expires = nil
+		}
+	}
+}
+
+// SetMany 批量写入 entries,每个分片只加一次锁。
+func (sm *ShardedMap[K, V]) SetMany(entries map[K]V) {
+	keys := make([]K, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sm.lockGroups(keys, func(s *shard[K, V], keys []K) {
+		for _, k := range keys {
+			s.data[k] = entries[k]
+		}
+	})
+}
+
+// DeleteMany 批量删除 keys,每个分片只加一次锁;返回实际删除的个数。
+func (sm *ShardedMap[K, V]) DeleteMany(keys ...K) int {
+	deleted := 0
+	sm.lockGroups(keys, func(s *shard[K, V], keys []K) {
+		for _, k := range keys {
+			if _, ok := s.data[k]; ok {
+				delete(s.data, k)
+				deleted++
+			}
+		}
+	})
+	return deleted
+}
+
+// ═══════════════════════════════════════════════════════
+// 跨分片遍历的内部实现(兼容迁移中的两代分片表)
+// ═══════════════════════════════════════════════════════
+
+// count 对每个存活分片求 fn 之和。迁移期间先对全部旧分片加读锁,
+// 使迁移无法推进,从而不会把同一条目在新旧分片中各数一次;
+// 新表中出现已迁移或 draining 的分片说明统计途中开始了新一轮迁移,按新的分片表重来
+func (sm *ShardedMap[K, V]) count(fn func(s *shard[K, V]) int) int {
+retry:
+	t := sm.table.Load()
+	for _, s := range t.prev {
+		s.mu.RLock()
+	}
+	n := 0
+	for _, s := range t.prev {
+		if !s.moved.Load() {
+			n += fn(s)
+		}
+	}
+	for _, s := range t.shards {
+		if !s.lock(false) {
+			unlockAll(t.prev, false)
+			goto retry
+		}
+		if s.draining {
+			s.mu.RUnlock()
+			unlockAll(t.prev, false)
+			goto retry
+		}
+		n += fn(s)
+		s.mu.RUnlock()
+	}
+	unlockAll(t.prev, false)
+	return n
+}
+
+// lockAll 按「旧表、新表,各自下标递增」的顺序锁住全部分片,返回加锁的分片(可能含已迁移的空分片)。
+// 新表中出现已迁移或 draining 的分片说明期间开始了新一轮迁移,释放后重试
+func (sm *ShardedMap[K, V]) lockAll(write bool) []*shard[K, V] {
+	for {
+		t := sm.table.Load()
+		all := make([]*shard[K, V], 0, len(t.prev)+len(t.shards))
+		all = append(append(all, t.prev...), t.shards...)
+		for _, s := range all {
+			if write {
+				s.mu.Lock()
+			} else {
+				s.mu.RLock()
+			}
+		}
+		stale := false
+		for _, s := range t.shards {
+			stale = stale || s.moved.Load() || s.draining
+		}
+		if !stale {
+			return all
+		}
+		unlockAll(all, write)
+	}
+}
+
+func unlockAll[K comparable, V any](shards []*shard[K, V], write bool) {
+	for _, s := range shards {
+		s.unlock(write)
+	}
+}
+
+// lockGroups 把 keys 按所在分片分组,每组只加一次写锁后调用 fn。
+// 迁移期间写入需要逐个 key 从旧分片拉到新分片,因此退化为逐个 key 加锁;
+// 分组后分片恰好开始迁移的那一组同样退化为逐个 key 加锁
+func (sm *ShardedMap[K, V]) lockGroups(keys []K, fn func(s *shard[K, V], keys []K)) {
+	perKey := func(ks []K) {
+		for i, k := range ks {
+			s := sm.lock(k)
+			fn(s, ks[i:i+1])
+			sm.unlock(s)
+		}
+	}
+
+	t := sm.table.Load()
+	if t.prev != nil {
+		perKey(keys)
+		return
+	}
+	groups := make(map[*shard[K, V]][]K)
+	for _, k := range keys {
+		s := t.shards[sm.hasher(k)%uint64(len(t.shards))]
+		groups[s] = append(groups[s], k)
+	}
+
+	for s, ks := range groups {
+		if s.lock(true) {
+			if !s.draining {
+				fn(s, ks)
+				sm.unlock(s)
+				continue
+			}
+			s.mu.Unlock()
+		}
+		perKey(ks)
+	}
+}
+
+// iterate 逐个分片复制条目(keep 非 nil 时只保留 keep 返回 true 的 key)并在锁外 yield。
+//
+// 迁移期间条目会从旧分片搬到新分片,为了不重复输出也不遗漏:
+//   - 每张分片表内先读旧表再读新表,key 只会从旧分片搬往新分片,因此不会被漏掉
+//   - 读到的分片若完整(未 draining),它哈希桶内此后出现在其他分片表中的 key 都已输出过,
+//     读其他分片表时按哈希反查,命中完整读过的分片就跳过
+//   - 读到的分片若已 draining,只记录从中输出的 key,读其他分片时逐个跳过
+//
+// 遍历完后若分片表已被替换(开始了新一轮迁移),继续遍历新表中尚未读过的分片。
+func (sm *ShardedMap[K, V]) iterate(keep func(s *shard[K, V], key K) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		type pair struct {
+			key   K
+			value V
+		}
+		buf := make([]pair, 0)
+		done := make(map[*shard[K, V]]bool)   // 已处理(含跳过的已迁移分片)
+		intact := make(map[*shard[K, V]]bool) // 读取时包含自己哈希桶的全部 key
+		var partial map[K]struct{}            // 从 draining 分片输出过的 key
+		var lists [][]*shard[K, V]            // 读过分片的各代分片表
+
+		// seen 判断 key 是否已从其他分片输出过
+		seen := func(list []*shard[K, V], key K) bool {
+			if _, ok := partial[key]; ok {
+				return true
+			}
+			h := sm.hasher(key)
+			for _, l := range lists {
+				if &l[0] != &list[0] && intact[l[h%uint64(len(l))]] {
+					return true
+				}
+			}
+			return false
+		}
+
+		for {
+			t := sm.table.Load()
+			for _, list := range [][]*shard[K, V]{t.prev, t.shards} {
+				for _, s := range list {
+					if done[s] {
+						continue
+					}
+					done[s] = true
+
+					buf = buf[:0]
+					s.mu.RLock()
+					if !s.moved.Load() {
+						dedup := partial != nil || len(lists) > 1 || (len(lists) == 1 && &lists[0][0] != &list[0])
+						for k, v := range s.data {
+							if keep != nil && !keep(s, k) {
+								continue
+							}
+							if dedup && seen(list, k) {
+								continue
+							}
+							buf = append(buf, pair{k, v})
+						}
+						if s.draining {
+							if partial == nil {
+								partial = make(map[K]struct{})
+							}
+							for _, p := range buf {
+								partial[p.key] = struct{}{}
+							}
+						} else {
+							intact[s] = true
+						}
+					}
+					s.mu.RUnlock()
+
+					if len(lists) == 0 || &lists[len(lists)-1][0] != &list[0] {
+						lists = append(lists, list)
+					}
+					for _, p := range buf {
+						if !yield(p.key, p.value) {
+							return
+						}
+					}
+				}
+			}
+			if sm.table.Load() == t {
+				return
+			}
+		}
+	}
+}
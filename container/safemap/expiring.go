@@ -0,0 +1,272 @@
+package safemap
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NoExpiration 是 TTL 对未设置过期时间的 key 的返回值。
+const NoExpiration time.Duration = -1
+
+const defaultSweepInterval = time.Second
+
+// ExpiringShardedMap 是支持逐条 TTL 的分片 map。
+//
+// 过期通过两种方式清理:
+//   - 惰性过期:Get / TTL / Touch 访问到已过期的 key 时立即删除
+//   - 后台清理:sweeper 每隔 sweepInterval 逐个分片扫描带 TTL 的 key,一次只锁一个分片
+//
+// 每个分片额外维护一张「带 TTL 的 key → 过期时刻」的索引,sweeper 只扫描这张索引;
+// 不带 TTL 的条目不进入索引,既不占额外内存,也不会被扫描。
+// 过期时刻基于单调时钟,不受系统时间调整影响。
+type ExpiringShardedMap[K comparable, V any] struct {
+	m        *ShardedMap[K, V]
+	clock    func() time.Duration // 单调时钟读数,默认为自创建起经过的时间;测试中可替换
+	onExpire atomic.Pointer[func(key K, value V)]
+	quit_ch  chan struct{}
+	once     sync.Once
+}
+
+// NewExpiringShardedMap 创建带后台 sweeper 的过期 map;shardCount <= 0 时取 32,
+// sweepInterval <= 0 时取 1s。不再使用时调用 Close 停止 sweeper。
+func NewExpiringShardedMap[K comparable, V any](shardCount int, sweepInterval time.Duration) *ExpiringShardedMap[K, V] {
+	if sweepInterval <= 0 {
+		sweepInterval = defaultSweepInterval
+	}
+	base := time.Now()
+	em := &ExpiringShardedMap[K, V]{
+		m:       NewShardedMap[K, V](shardCount),
+		clock:   func() time.Duration { return time.Since(base) },
+		quit_ch: make(chan struct{}),
+	}
+	go em.cron_sweep(sweepInterval)
+	return em
+}
+
+// OnExpire 设置过期回调,条目因过期被删除(惰性或 sweeper)时调用;Delete 和覆盖写入不会触发。
+// 回调在锁外执行,可以安全地访问本 map。传 nil 取消回调。
+func (em *ExpiringShardedMap[K, V]) OnExpire(fn func(key K, value V)) {
+	if fn == nil {
+		em.onExpire.Store(nil)
+		return
+	}
+	em.onExpire.Store(&fn)
+}
+
+// Close 停止后台 sweeper;之后 map 仍可使用,但只剩惰性过期。可重复调用。
+func (em *ExpiringShardedMap[K, V]) Close() {
+	em.once.Do(func() { close(em.quit_ch) })
+}
+
+// now 返回单调时钟读数
+func (em *ExpiringShardedMap[K, V]) now() int64 {
+	return int64(em.clock())
+}
+
+// Set 写入不过期的条目;key 原有的 TTL 被清除。
+func (em *ExpiringShardedMap[K, V]) Set(key K, value V) {
+	s := em.m.lock(key)
+	defer em.m.unlock(s)
+	s.data[key] = value
+	delete(s.expires, key)
+}
+
+// SetWithTTL 写入在 ttl 后过期的条目;ttl <= 0 时等同于 Set。
+func (em *ExpiringShardedMap[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	if ttl <= 0 {
+		em.Set(key, value)
+		return
+	}
+	s := em.m.lock(key)
+	defer em.m.unlock(s)
+	s.data[key] = value
+	if s.expires == nil {
+		s.expires = make(map[K]int64)
+	}
+	s.expires[key] = em.now() + int64(ttl)
+}
+
+// Get 返回未过期的值;key 已过期时顺便删除并触发 OnExpire。
+func (em *ExpiringShardedMap[K, V]) Get(key K) (V, bool) {
+	s := em.m.rlock(key)
+	val, ok := s.data[key]
+	at, hasTTL := s.expires[key]
+	s.mu.RUnlock()
+
+	if ok && hasTTL && at <= em.now() {
+		em.expire(key)
+		var zero V
+		return zero, false
+	}
+	return val, ok
+}
+
+// Delete 删除 key,不触发 OnExpire。
+func (em *ExpiringShardedMap[K, V]) Delete(key K) {
+	s := em.m.lock(key)
+	defer em.m.unlock(s)
+	delete(s.data, key)
+	delete(s.expires, key)
+}
+
+// TTL 返回 key 的剩余存活时间;key 没有 TTL 时返回 NoExpiration。
+// key 不存在或已过期时 ok 为 false。
+func (em *ExpiringShardedMap[K, V]) TTL(key K) (remaining time.Duration, ok bool) {
+	s := em.m.rlock(key)
+	_, ok = s.data[key]
+	at, hasTTL := s.expires[key]
+	s.mu.RUnlock()
+
+	if !ok {
+		return 0, false
+	}
+	if !hasTTL {
+		return NoExpiration, true
+	}
+	if remaining = time.Duration(at - em.now()); remaining <= 0 {
+		em.expire(key)
+		return 0, false
+	}
+	return remaining, true
+}
+
+// Touch 把 key 的过期时刻重置为 now + ttl,用于续期会话等场景;ttl <= 0 时移除 TTL,使其永不过期。
+// key 不存在或已过期时返回 false。
+func (em *ExpiringShardedMap[K, V]) Touch(key K, ttl time.Duration) bool {
+	s := em.m.lock(key)
+	if _, ok := s.data[key]; !ok {
+		s.mu.Unlock()
+		return false
+	}
+	now := em.now()
+	if at, hasTTL := s.expires[key]; hasTTL && at <= now {
+		s.mu.Unlock()
+		em.expire(key)
+		return false
+	}
+	if ttl <= 0 {
+		delete(s.expires, key)
+	} else {
+		if s.expires == nil {
+			s.expires = make(map[K]int64)
+		}
+		s.expires[key] = now + int64(ttl)
+	}
+	s.mu.Unlock()
+	return true
+}
+
+// Len 返回未过期的条目数(弱一致,见 ShardedMap 的说明)。
+func (em *ExpiringShardedMap[K, V]) Len() int {
+	now := em.now()
+	return em.m.count(func(s *shard[K, V]) int {
+		n := len(s.data)
+		for _, at := range s.expires {
+			if at <= now {
+				n--
+			}
+		}
+		return n
+	})
+}
+
+// Range 对每个未过期的条目调用 fn,fn 返回 false 时提前停止(无序,弱一致)。
+// 与 ShardedMap.Range 一样,fn 在锁外执行。
+func (em *ExpiringShardedMap[K, V]) Range(fn func(key K, value V) bool) {
+	now := em.now()
+	live := func(s *shard[K, V], key K) bool {
+		at, hasTTL := s.expires[key]
+		return !hasTTL || at > now
+	}
+	for k, v := range em.m.iterate(live) {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// Clear 删除全部条目(原子),不触发 OnExpire。
+func (em *ExpiringShardedMap[K, V]) Clear() {
+	em.m.Clear()
+}
+
+// Resize 在线调整分片数,TTL 随条目一起迁移,见 ShardedMap.Resize。
+func (em *ExpiringShardedMap[K, V]) Resize(newShardCount int) error {
+	return em.m.Resize(newShardCount)
+}
+
+// SetAutoResize 设置自动扩容策略,见 ShardedMap.SetAutoResize。
+func (em *ExpiringShardedMap[K, V]) SetAutoResize(policy *AutoResizePolicy) {
+	em.m.SetAutoResize(policy)
+}
+
+// ResizeStatus 返回迁移进度。
+func (em *ExpiringShardedMap[K, V]) ResizeStatus() ResizeStatus {
+	return em.m.ResizeStatus()
+}
+
+// expire 在写锁内复查并删除已过期的 key,删除成功时在锁外触发回调
+func (em *ExpiringShardedMap[K, V]) expire(key K) {
+	s := em.m.lock(key)
+	at, hasTTL := s.expires[key]
+	if !hasTTL || at > em.now() {
+		// 读锁释放后被重新写入或续期
+		s.mu.Unlock()
+		return
+	}
+	val := s.data[key]
+	delete(s.data, key)
+	delete(s.expires, key)
+	s.mu.Unlock()
+
+	if fn := em.onExpire.Load(); fn != nil {
+		(*fn)(key, val)
+	}
+}
+
+// sweep 逐个分片清理已过期的条目
+func (em *ExpiringShardedMap[K, V]) sweep() {
+	type pair struct {
+		key   K
+		value V
+	}
+	expired := make([]pair, 0)
+	t := em.m.table.Load()
+	for _, s := range append(append([]*shard[K, V](nil), t.prev...), t.shards...) {
+		expired = expired[:0]
+		now := em.now()
+		if !s.lock(true) {
+			// 已迁移到新分片,下一轮再清理
+			continue
+		}
+		for k, at := range s.expires {
+			if at <= now {
+				expired = append(expired, pair{k, s.data[k]})
+				delete(s.data, k)
+				delete(s.expires, k)
+			}
+		}
+		s.mu.Unlock()
+
+		if fn := em.onExpire.Load(); fn != nil {
+			for _, p := range expired {
+				(*fn)(p.key, p.value)
+			}
+		}
+	}
+}
+
+func (em *ExpiringShardedMap[K, V]) cron_sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-em.quit_ch:
+			return
+		case <-ticker.C:
+			em.sweep()
+		}
+	}
+}
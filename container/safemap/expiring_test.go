@@ -0,0 +1,263 @@
+package safemap
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock 是可手动推进的单调时钟
+type fakeClock struct {
+	now atomic.Int64
+}
+
+func (c *fakeClock) read() time.Duration { return time.Duration(c.now.Load()) }
+
+func (c *fakeClock) advance(d time.Duration) { c.now.Add(int64(d)) }
+
+// newTestExpiring 创建使用 fakeClock、不会自动清理的过期 map,并记录 OnExpire 的调用
+func newTestExpiring(t *testing.T) (*ExpiringShardedMap[string, int], *fakeClock, func() map[string]int) {
+	t.Helper()
+	clock := &fakeClock{}
+	em := NewExpiringShardedMap[string, int](8, time.Hour)
+	em.clock = clock.read
+	t.Cleanup(em.Close)
+
+	var mu sync.Mutex
+	fired := make(map[string]int)
+	em.OnExpire(func(key string, value int) {
+		mu.Lock()
+		defer mu.Unlock()
+		fired[key]++
+	})
+	snapshot := func() map[string]int {
+		mu.Lock()
+		defer mu.Unlock()
+		out := make(map[string]int, len(fired))
+		for k, n := range fired {
+			out[k] = n
+		}
+		return out
+	}
+	return em, clock, snapshot
+}
+
+// TestExpiringLazyGet Get 访问到已过期的 key 时删除它并触发一次 OnExpire
+func TestExpiringLazyGet(t *testing.T) {
+	em, clock, fired := newTestExpiring(t)
+	em.SetWithTTL("a", 1, 10*time.Second)
+
+	clock.advance(5 * time.Second)
+	if v, ok := em.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get before expiry = %d, %v", v, ok)
+	}
+	if ttl, ok := em.TTL("a"); !ok || ttl != 5*time.Second {
+		t.Fatalf("TTL = %v, %v, want 5s", ttl, ok)
+	}
+	if em.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", em.Len())
+	}
+
+	clock.advance(5 * time.Second)
+	if em.Len() != 0 {
+		t.Fatalf("Len counts an expired entry: %d", em.Len())
+	}
+	if _, ok := em.Get("a"); ok {
+		t.Fatal("Get returned an expired entry")
+	}
+	if _, ok := em.Get("a"); ok {
+		t.Fatal("second Get returned an expired entry")
+	}
+	if got := fired(); got["a"] != 1 || len(got) != 1 {
+		t.Fatalf("OnExpire calls = %v, want a once", got)
+	}
+}
+
+// TestExpiringTTL 没有 TTL 的 key 返回 NoExpiration,不存在的 key 返回 false
+func TestExpiringTTL(t *testing.T) {
+	em, clock, _ := newTestExpiring(t)
+	em.Set("forever", 1)
+	em.SetWithTTL("zero", 2, 0)
+
+	for _, k := range []string{"forever", "zero"} {
+		if ttl, ok := em.TTL(k); !ok || ttl != NoExpiration {
+			t.Fatalf("TTL(%q) = %v, %v, want NoExpiration", k, ttl, ok)
+		}
+	}
+	if _, ok := em.TTL("missing"); ok {
+		t.Fatal("TTL of a missing key: ok = true")
+	}
+
+	em.SetWithTTL("short", 3, time.Second)
+	clock.advance(time.Second)
+	if _, ok := em.TTL("short"); ok {
+		t.Fatal("TTL of an expired key: ok = true")
+	}
+}
+
+// TestExpiringTouch Touch 把过期时刻重置为 now + ttl,ttl <= 0 时移除 TTL
+func TestExpiringTouch(t *testing.T) {
+	em, clock, fired := newTestExpiring(t)
+	em.SetWithTTL("s", 1, 10*time.Second)
+
+	clock.advance(8 * time.Second)
+	if !em.Touch("s", 10*time.Second) {
+		t.Fatal("Touch on a live key = false")
+	}
+	clock.advance(8 * time.Second)
+	if ttl, ok := em.TTL("s"); !ok || ttl != 2*time.Second {
+		t.Fatalf("TTL after Touch = %v, %v, want 2s", ttl, ok)
+	}
+
+	if !em.Touch("s", 0) {
+		t.Fatal("Touch(ttl=0) = false")
+	}
+	clock.advance(time.Hour)
+	if ttl, ok := em.TTL("s"); !ok || ttl != NoExpiration {
+		t.Fatalf("TTL after Touch(0) = %v, %v, want NoExpiration", ttl, ok)
+	}
+
+	if em.Touch("missing", time.Second) {
+		t.Fatal("Touch on a missing key = true")
+	}
+	em.SetWithTTL("gone", 2, time.Second)
+	clock.advance(time.Second)
+	if em.Touch("gone", time.Hour) {
+		t.Fatal("Touch on an expired key = true")
+	}
+	if got := fired(); got["gone"] != 1 || len(got) != 1 {
+		t.Fatalf("OnExpire calls = %v, want gone once", got)
+	}
+}
+
+// TestExpiringSetClearsTTL 覆盖写入清除旧 TTL,且 Delete / 覆盖都不触发 OnExpire
+func TestExpiringSetClearsTTL(t *testing.T) {
+	em, clock, fired := newTestExpiring(t)
+	em.SetWithTTL("overwritten", 1, time.Second)
+	em.Set("overwritten", 2)
+	em.SetWithTTL("deleted", 3, time.Second)
+	em.Delete("deleted")
+	em.SetWithTTL("renewed", 4, time.Second)
+	em.SetWithTTL("renewed", 5, time.Minute)
+
+	clock.advance(2 * time.Second)
+	em.sweep()
+
+	if v, ok := em.Get("overwritten"); !ok || v != 2 {
+		t.Fatalf("Get(overwritten) = %d, %v, want 2 without TTL", v, ok)
+	}
+	if ttl, _ := em.TTL("overwritten"); ttl != NoExpiration {
+		t.Fatalf("TTL(overwritten) = %v, want NoExpiration", ttl)
+	}
+	if v, ok := em.Get("renewed"); !ok || v != 5 {
+		t.Fatalf("Get(renewed) = %d, %v, want 5", v, ok)
+	}
+	if _, ok := em.Get("deleted"); ok {
+		t.Fatal("deleted key came back")
+	}
+	if got := fired(); len(got) != 0 {
+		t.Fatalf("OnExpire fired for %v, want none", got)
+	}
+}
+
+// TestExpiringOnExpireOnce sweeper 与惰性 Get 并发清理时,每个条目的 OnExpire 恰好触发一次
+func TestExpiringOnExpireOnce(t *testing.T) {
+	const n = 2_000
+	em, clock, fired := newTestExpiring(t)
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = stringKey(i)
+		em.SetWithTTL(keys[i], i, time.Second)
+	}
+	em.Set("keep", -1)
+
+	clock.advance(time.Second)
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		em.sweep()
+	}()
+	for _, rev := range []bool{false, true} {
+		go func() {
+			defer wg.Done()
+			for i := range keys {
+				if rev {
+					i = n - 1 - i
+				}
+				if _, ok := em.Get(keys[i]); ok {
+					t.Errorf("Get(%q) returned an expired entry", keys[i])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	em.sweep()
+
+	got := fired()
+	if len(got) != n {
+		t.Fatalf("OnExpire fired for %d keys, want %d", len(got), n)
+	}
+	for k, c := range got {
+		if c != 1 {
+			t.Fatalf("OnExpire fired %d times for %q", c, k)
+		}
+	}
+	if em.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", em.Len())
+	}
+	count := 0
+	em.Range(func(key string, value int) bool {
+		count++
+		return true
+	})
+	if count != 1 {
+		t.Fatalf("Range yielded %d entries, want 1", count)
+	}
+}
+
+// TestExpiringResizeKeepsTTL 迁移时 TTL 随条目一起搬到新分片
+func TestExpiringResizeKeepsTTL(t *testing.T) {
+	em, clock, _ := newTestExpiring(t)
+	for i := range 1_000 {
+		em.SetWithTTL(stringKey(i), i, time.Duration(i+1)*time.Second)
+	}
+	if err := em.Resize(32); err != nil {
+		t.Fatal(err)
+	}
+	waitResize(t, em.m)
+
+	clock.advance(500 * time.Second)
+	for i := range 1_000 {
+		ttl, ok := em.TTL(stringKey(i))
+		if want := time.Duration(i+1-500) * time.Second; i >= 500 && (!ok || ttl != want) {
+			t.Fatalf("TTL(%d) = %v, %v, want %v", i, ttl, ok, want)
+		}
+		if i < 500 && ok {
+			t.Fatalf("TTL(%d) ok after expiry", i)
+		}
+	}
+}
+
+// TestExpiringSweeper 后台 sweeper 使用真实时钟清理过期条目
+func TestExpiringSweeper(t *testing.T) {
+	em := NewExpiringShardedMap[int, int](4, 5*time.Millisecond)
+	defer em.Close()
+	expired := make(chan int, 10)
+	em.OnExpire(func(key, value int) { expired <- key })
+
+	em.SetWithTTL(1, 1, 10*time.Millisecond)
+	select {
+	case k := <-expired:
+		if k != 1 {
+			t.Fatalf("expired key %d, want 1", k)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("sweeper did not expire the entry")
+	}
+	if em.m.Len() != 0 {
+		t.Fatalf("underlying map still holds %d entries", em.m.Len())
+	}
+	em.Close()
+}
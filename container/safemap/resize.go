@@ -0,0 +1,241 @@
+package safemap
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"runtime"
+)
+
+// ═══════════════════════════════════════════════════════
+// 在线扩缩容:渐进式迁移
+// ═══════════════════════════════════════════════════════
+//
+// 与 Redis 的渐进式 rehash 类似,Resize 只负责装上新分片表,数据分批迁移到新表,期间读写照常进行:
+//   - 迁移期间同时存在旧表 prev 与新表 shards,每个 key 在任一时刻只存在于「旧分片」与「新分片」之一
+//   - 后台 goroutine 逐个旧分片迁移,每次持有旧分片写锁最多搬 migrateBatch 个条目(连同 TTL),
+//     批次之间释放锁;旧分片搬空后标记 moved
+//   - 读:同时持有旧、新分片的读锁,先查新分片再查旧分片
+//   - 写:持有旧分片写锁时先顺带迁移 writeStepBatch 个条目,再把 key 从旧分片拉到新分片,之后只写新分片;
+//     因此迁移进度不只依赖后台 goroutine
+//   - 全部旧分片迁移完后丢弃旧表
+//
+// 单次持锁的停顿以批次大小为上限,与分片大小无关;分片很少、单个分片很大时也不会让整个 map 停顿。
+//
+// 旧分片一旦有条目被迁走(或有 key 被写进新分片)就标记 draining:此后它不再包含自己哈希桶的全部 key,
+// 只按单个分片读写它是不正确的,遇到时重新读取分片表,走迁移路径。
+//
+// 加锁顺序始终是「先旧表、后新表」,同一张表内最多同时持有一把分片锁(lockAll / count 除外,它们按下标递增加锁)。
+
+const (
+	migrateBatch   = 1024 // 后台迁移每次持锁最多搬动的条目数
+	writeStepBatch = 16   // 每次写入迁移中的旧分片时顺带搬动的条目数
+)
+
+// shardTable 是一代分片表
+type shardTable[K comparable, V any] struct {
+	shards []*shard[K, V]
+	prev   []*shard[K, V] // 迁移中的旧分片;nil 表示不在迁移
+}
+
+// ResizeStatus 描述迁移进度。
+type ResizeStatus struct {
+	Resizing bool
+	From     int // 旧分片数;不在迁移时等于 To
+	To       int // 当前(目标)分片数
+	Migrated int // 已迁移的旧分片数
+}
+
+// Progress 返回迁移进度,取值 [0, 1];不在迁移时为 1。
+func (rs ResizeStatus) Progress() float64 {
+	if !rs.Resizing || rs.From == 0 {
+		return 1
+	}
+	return float64(rs.Migrated) / float64(rs.From)
+}
+
+// AutoResizePolicy 自动扩容策略:平均分片大小超过 MaxAvgShardSize 时,
+// 分片数反复乘以 Factor,直到平均分片大小回到阈值以内或达到 MaxShards。
+type AutoResizePolicy struct {
+	MaxAvgShardSize int // 必须 > 0
+	Factor          int // <= 1 时取 2
+	MaxShards       int // 分片数上限,<= 0 表示不限
+}
+
+// ShardCount 返回当前(迁移中则为目标)分片数。
+func (sm *ShardedMap[K, V]) ShardCount() int {
+	return len(sm.table.Load().shards)
+}
+
+// ResizeStatus 返回当前的迁移进度。
+func (sm *ShardedMap[K, V]) ResizeStatus() ResizeStatus {
+	t := sm.table.Load()
+	st := ResizeStatus{From: len(t.shards), To: len(t.shards)}
+	if t.prev == nil {
+		return st
+	}
+	st.Resizing = true
+	st.From = len(t.prev)
+	for _, s := range t.prev {
+		if s.moved.Load() {
+			st.Migrated++
+		}
+	}
+	return st
+}
+
+// Resize 把分片数调整为 newShardCount(可增可减),立即返回,迁移在后台渐进完成。
+// 上一次迁移尚未完成时返回错误;分片数不变时什么也不做。
+func (sm *ShardedMap[K, V]) Resize(newShardCount int) error {
+	if newShardCount <= 0 {
+		return fmt.Errorf("failed to resize: shard count must be > 0, got %d", newShardCount)
+	}
+
+	sm.resizeMu.Lock()
+	defer sm.resizeMu.Unlock()
+
+	t := sm.table.Load()
+	if t.prev != nil {
+		return fmt.Errorf("failed to resize: migration %d → %d still in progress", len(t.prev), len(t.shards))
+	}
+	if newShardCount == len(t.shards) {
+		return nil
+	}
+
+	next := &shardTable[K, V]{shards: newShards[K, V](newShardCount), prev: t.shards}
+	sm.table.Store(next)
+	go sm.migrate(next)
+	return nil
+}
+
+// SetAutoResize 设置自动扩容策略,nil 表示关闭。
+// 写入使某个分片超过 MaxAvgShardSize 时才会计算平均分片大小,不在迁移时才会触发 Resize。
+func (sm *ShardedMap[K, V]) SetAutoResize(policy *AutoResizePolicy) {
+	if policy == nil {
+		sm.autoResize.Store(nil)
+		return
+	}
+	p := *policy
+	if p.MaxAvgShardSize <= 0 {
+		panic("ShardedMap: MaxAvgShardSize must be > 0")
+	}
+	if p.Factor <= 1 {
+		p.Factor = 2
+	}
+	sm.autoResize.Store(&p)
+}
+
+// maybeAutoResize 由写操作在释放分片锁后调用,n 是该分片当时的大小
+func (sm *ShardedMap[K, V]) maybeAutoResize(n int) {
+	p := sm.autoResize.Load()
+	if p == nil || n <= p.MaxAvgShardSize {
+		return
+	}
+	// 分片超限后每增长 stride 个条目才检查一次,避免每次写入都统计全表
+	if stride := min(64, max(1, p.MaxAvgShardSize/8)); n%stride != 0 {
+		return
+	}
+	t := sm.table.Load()
+	if t.prev != nil {
+		return
+	}
+	count := len(t.shards)
+	total := sm.Len()
+	if total/count <= p.MaxAvgShardSize {
+		return
+	}
+	// 一次扩到足以把平均分片大小压回阈值以内,避免突发写入时连续多轮迁移
+	target := count
+	for total/target > p.MaxAvgShardSize && (p.MaxShards <= 0 || target < p.MaxShards) {
+		target *= p.Factor
+	}
+	if p.MaxShards > 0 {
+		target = min(target, p.MaxShards)
+	}
+	if target > count {
+		_ = sm.Resize(target)
+	}
+}
+
+// migrate 逐个迁移旧分片,每批最多 migrateBatch 个条目,批次之间释放锁;全部完成后丢弃旧表
+func (sm *ShardedMap[K, V]) migrate(t *shardTable[K, V]) {
+	for _, old := range t.prev {
+		for {
+			old.mu.Lock()
+			if !old.moved.Load() {
+				sm.migrate_step(t, old, migrateBatch)
+			}
+			moved := old.moved.Load()
+			old.mu.Unlock()
+			if moved {
+				break
+			}
+			runtime.Gosched()
+		}
+	}
+
+	sm.resizeMu.Lock()
+	sm.table.Store(&shardTable[K, V]{shards: t.shards})
+	sm.resizeMu.Unlock()
+}
+
+// migrate_step 在持有旧分片写锁时把最多 limit 个条目搬到新表,按新分片分组,每个新分片只加一次锁;
+// 旧分片搬空后标记 moved
+func (sm *ShardedMap[K, V]) migrate_step(t *shardTable[K, V], old *shard[K, V], limit int) {
+	old.draining = true
+
+	// 旧分片在迁移期间只减不增,沿用同一个迭代器即可逐批取出剩余 key;
+	// 每批重新 range 会反复扫描已搬空的槽位,大分片上退化为平方复杂度
+	if old.next == nil {
+		old.next, old.stop = iter.Pull(maps.Keys(old.data))
+	}
+	groups := make(map[*shard[K, V]][]K)
+	for n := 0; n < limit; n++ {
+		k, ok := old.next()
+		if !ok {
+			old.stop()
+			old.next, old.stop = nil, nil
+			break
+		}
+		ns := t.shards[sm.hasher(k)%uint64(len(t.shards))]
+		groups[ns] = append(groups[ns], k)
+	}
+	for ns, keys := range groups {
+		ns.mu.Lock()
+		for _, k := range keys {
+			move_entry(old, ns, k)
+		}
+		ns.mu.Unlock()
+	}
+	old.finish_if_empty()
+}
+
+// move_entry 把 key 连同 TTL 从 from 搬到 to,调用方持有两者的写锁
+func move_entry[K comparable, V any](from, to *shard[K, V], key K) {
+	val, ok := from.data[key]
+	if !ok {
+		return
+	}
+	to.data[key] = val
+	delete(from.data, key)
+	if at, ok := from.expires[key]; ok {
+		if to.expires == nil {
+			to.expires = make(map[K]int64)
+		}
+		to.expires[key] = at
+		delete(from.expires, key)
+	}
+}
+
+// finish_if_empty 旧分片搬空后标记 moved,调用方持有写锁
+func (s *shard[K, V]) finish_if_empty() {
+	if len(s.data) == 0 {
+		if s.stop != nil {
+			s.stop()
+			s.next, s.stop = nil, nil
+		}
+		s.data = nil
+		s.expires = nil
+		s.moved.Store(true)
+	}
+}
@@ -1,132 +1,187 @@
 package safemap
 
 import (
-	"fmt"
+	"hash/maphash"
 	"sync"
-	"unsafe"
+	"sync/atomic"
 )
 
 type shard[K comparable, V any] struct {
-	mu   sync.RWMutex
-	data map[K]V
+	mu      sync.RWMutex
+	data    map[K]V
+	expires map[K]int64 // 仅 ExpiringShardedMap 使用:带 TTL 的 key → 过期时刻,普通 ShardedMap 中恒为 nil
+	moved   atomic.Bool // 已整体迁移到新分片表;只在持有写锁时置位,之后 data 不再使用
+	// draining 由 mu 保护:作为迁移中的旧分片,已有条目被迁走或有 key 被写进新分片,
+	// 不再包含自己哈希桶的全部 key
+	draining bool
+	next     func() (K, bool) // 迁移用的 key 迭代器,由 mu 保护,见 migrate_step
+	stop     func()
 }
 
+// lock 加锁;分片已被迁移时立即释放并返回 false
+func (s *shard[K, V]) lock(write bool) bool {
+	if write {
+		s.mu.Lock()
+	} else {
+		s.mu.RLock()
+	}
+	if !s.moved.Load() {
+		return true
+	}
+	s.unlock(write)
+	return false
+}
+
+func (s *shard[K, V]) unlock(write bool) {
+	if write {
+		s.mu.Unlock()
+	} else {
+		s.mu.RUnlock()
+	}
+}
+
+// Hasher 把 key 映射为 64 位哈希值,用于选择分片。
+// 必须满足:相等的 key 得到相同的哈希值。
+type Hasher[K comparable] func(key K) uint64
+
 type ShardedMap[K comparable, V any] struct {
-	shards []*shard[K, V]
+	table      atomic.Pointer[shardTable[K, V]]
+	hasher     Hasher[K]
+	resizeMu   sync.Mutex // 串行化分片表的替换
+	autoResize atomic.Pointer[AutoResizePolicy]
 }
 
+// NewShardedMap 创建分片 map,shardCount <= 0 时取 32。
+// 分片选择使用 maphash.Comparable,每个 map 各自随机生成种子,
+// 对任意 comparable key(结构体、数组、接口等)都按完整内容哈希。
 func NewShardedMap[K comparable, V any](shardCount int) *ShardedMap[K, V] {
+	seed := maphash.MakeSeed()
+	return NewShardedMapWithHasher[K, V](shardCount, func(key K) uint64 {
+		return maphash.Comparable(seed, key)
+	})
+}
+
+// NewShardedMapWithHasher 使用自定义 hasher 创建分片 map;hasher 为 nil 时 panic。
+func NewShardedMapWithHasher[K comparable, V any](shardCount int, hasher Hasher[K]) *ShardedMap[K, V] {
+	if hasher == nil {
+		panic("ShardedMap: hasher function cannot be nil")
+	}
 	if shardCount <= 0 {
 		shardCount = 32
 	}
-	sm := &ShardedMap[K, V]{
-		shards: make([]*shard[K, V], shardCount),
-	}
-	for i := range sm.shards {
-		sm.shards[i] = &shard[K, V]{data: make(map[K]V)}
-	}
+	sm := &ShardedMap[K, V]{hasher: hasher}
+	sm.table.Store(&shardTable[K, V]{shards: newShards[K, V](shardCount)})
 	return sm
 }
 
-func (sm *ShardedMap[K, V]) getShard(key K) *shard[K, V] {
-	return sm.shards[hash(key)%uint64(len(sm.shards))]
-}
-
-// hash 根据类型选择最优哈希算法
-func hash[K comparable](key K) uint64 {
-	switch v := any(key).(type) {
-	case string:
-		return hashString(v)
-	case int:
-		return uint64(v)
-	case int8:
-		return uint64(v)
-	case int16:
-		return uint64(v)
-	case int32:
-		return uint64(v)
-	case int64:
-		return uint64(v)
-	case uint:
-		return uint64(v)
-	case uint8:
-		return uint64(v)
-	case uint16:
-		return uint64(v)
-	case uint32:
-		return uint64(v)
-	case uint64:
-		return v
-	case uintptr:
-		return uint64(v)
-	default:
-		return hashAny(key)
+func newShards[K comparable, V any](n int) []*shard[K, V] {
+	shards := make([]*shard[K, V], n)
+	for i := range shards {
+		shards[i] = &shard[K, V]{data: make(map[K]V)}
 	}
+	return shards
 }
 
-// FNV-1a 64bit 字符串哈希
-func hashString(s string) uint64 {
-	h := uint64(14695981039346656037)
-	for i := 0; i < len(s); i++ {
-		h ^= uint64(s[i])
-		h *= 1099511628211
+// lockShard 返回 key 应当读写的分片,返回时已持有其锁(write 决定读锁或写锁)。
+//
+// 不在迁移时就是 key 所在的分片。迁移期间 key 的旧分片尚未搬空时:
+// 读返回新旧分片中含有 key 的那一个(都没有时返回新分片);写先把 key 拉到新分片,再返回新分片。
+// 遇到已迁移或 draining 的分片说明分片表已被替换,重新读取分片表后重试。
+func (sm *ShardedMap[K, V]) lockShard(key K, write bool) *shard[K, V] {
+	h := sm.hasher(key)
+	for {
+		t := sm.table.Load()
+		n := t.shards[h%uint64(len(t.shards))]
+		if t.prev != nil {
+			if s, ok := sm.lockMigrating(t, t.prev[h%uint64(len(t.prev))], n, key, write); ok {
+				return s
+			}
+		}
+		if n.lock(write) {
+			if !n.draining {
+				return n
+			}
+			n.unlock(write)
+		}
 	}
-	return h
-}
-
-// 其他类型的 fallback 哈希
-// 利用 unsafe 读取内存内容作为哈希值
-func hashAny[K comparable](key K) uint64 {
-	size := unsafe.Sizeof(key)
-	switch size {
-	case 0:
-		return 0
-	case 1:
-		return uint64(*(*uint8)(unsafe.Pointer(&key)))
-	case 2:
-		return uint64(*(*uint16)(unsafe.Pointer(&key)))
-	case 4:
-		return uint64(*(*uint32)(unsafe.Pointer(&key)))
-	case 8:
-		return *(*uint64)(unsafe.Pointer(&key))
-	default:
-		return *(*uint64)(unsafe.Pointer(&key))
+}
+
+// lockMigrating 处理迁移期间旧分片 p 尚未搬空的情形;p 已搬空时返回 false,改为直接使用新分片
+func (sm *ShardedMap[K, V]) lockMigrating(t *shardTable[K, V], p, n *shard[K, V], key K, write bool) (*shard[K, V], bool) {
+	if !p.lock(write) {
+		return nil, false
+	}
+
+	if !write {
+		n.mu.RLock()
+		if _, ok := n.data[key]; ok {
+			p.mu.RUnlock()
+			return n, true
+		}
+		if _, ok := p.data[key]; ok {
+			n.mu.RUnlock()
+			return p, true
+		}
+		p.mu.RUnlock()
+		return n, true
 	}
+
+	// 写入顺带推进迁移,进度不只依赖后台 goroutine
+	sm.migrate_step(t, p, writeStepBatch)
+	n.mu.Lock()
+	if !p.moved.Load() {
+		move_entry(p, n, key)
+		p.finish_if_empty()
+	}
+	p.mu.Unlock()
+	return n, true
+}
+
+// lock / rlock 是 lockShard 的简写
+func (sm *ShardedMap[K, V]) lock(key K) *shard[K, V] {
+	return sm.lockShard(key, true)
+}
+
+func (sm *ShardedMap[K, V]) rlock(key K) *shard[K, V] {
+	return sm.lockShard(key, false)
+}
+
+// unlock 释放写锁,并按分片大小检查是否需要自动扩容
+func (sm *ShardedMap[K, V]) unlock(s *shard[K, V]) {
+	n := len(s.data)
+	s.mu.Unlock()
+	sm.maybeAutoResize(n)
 }
 
 func (sm *ShardedMap[K, V]) Get(key K) (V, bool) {
-	s := sm.getShard(key)
-	s.mu.RLock()
+	s := sm.rlock(key)
 	defer s.mu.RUnlock()
 	val, ok := s.data[key]
 	return val, ok
 }
 
 func (sm *ShardedMap[K, V]) Set(key K, value V) {
-	s := sm.getShard(key)
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s := sm.lock(key)
+	defer sm.unlock(s)
 	s.data[key] = value
 }
 
 func (sm *ShardedMap[K, V]) Delete(key K) {
-	s := sm.getShard(key)
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s := sm.lock(key)
+	defer sm.unlock(s)
 	delete(s.data, key)
 }
 
 func (sm *ShardedMap[K, V]) GetOrStore(key K, value V) (actual V, loaded bool) {
-	s := sm.getShard(key)
-	s.mu.RLock()
+	s := sm.rlock(key)
 	if val, ok := s.data[key]; ok {
 		s.mu.RUnlock()
 		return val, true
 	}
 	s.mu.RUnlock()
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s = sm.lock(key)
+	defer sm.unlock(s)
 	if val, ok := s.data[key]; ok {
 		return val, true
 	}
@@ -134,22 +189,21 @@ func (sm *ShardedMap[K, V]) GetOrStore(key K, value V) (actual V, loaded bool) {
 	return value, false
 }
 
-func (sm *ShardedMap[K, V]) Compute(key K, computeAbsent func() V, computePresent func(V) V) error {
-	s := sm.getShard(key)
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if val, ok := s.data[key]; ok && computePresent != nil {
-		newVal := computePresent(val)
-		s.data[key] = newVal
-		return nil
+// Compute 在分片锁内原子地计算 key 的新值:key 存在且 computePresent 非 nil 时写入 computePresent(旧值),
+// 否则写入 computeAbsent()(key 存在但 computePresent 为 nil 时同样用它覆盖旧值);
+// 两者都不适用时保持原状。返回调用前 key 是否存在。
+func (sm *ShardedMap[K, V]) Compute(key K, computeAbsent func() V, computePresent func(V) V) (present bool) {
+	s := sm.lock(key)
+	defer sm.unlock(s)
+
+	val, present := s.data[key]
+	if present && computePresent != nil {
+		s.data[key] = computePresent(val)
+		return true
 	}
 
 	if computeAbsent != nil {
-		val := computeAbsent()
-		s.data[key] = val
-		return nil
+		s.data[key] = computeAbsent()
 	}
-
-	return fmt.Errorf("invalid parameters")
+	return present
 }
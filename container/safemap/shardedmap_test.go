@@ -0,0 +1,398 @@
+package safemap
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+)
+
+const distShards = 32
+
+// structKey 前 8 字节(ID)相同,只在之后的字段上不同;旧实现会把它们全部放进同一个分片
+type structKey struct {
+	ID   int64
+	Seq  int64
+	Name string
+}
+
+type arrayKey [4]uint32
+
+func intKey(i int) int            { return i }
+func stringKey(i int) string      { return fmt.Sprintf("user:%d", i) }
+func structKeyOf(i int) structKey { return structKey{ID: 1, Seq: int64(i), Name: stringKey(i % 7)} }
+func arrayKeyOf(i int) arrayKey   { return arrayKey{0, 0, 0, uint32(i)} }
+
+// checkDistribution 写入 n 个 key,要求每个分片的负载都落在均值的 ±50% 内
+func checkDistribution[K comparable](t *testing.T, key func(int) K) {
+	t.Helper()
+	const n = 32_000
+	sm := NewShardedMap[K, int](distShards)
+	for i := range n {
+		sm.Set(key(i), i)
+	}
+	if sm.Len() != n {
+		t.Fatalf("Len = %d, want %d", sm.Len(), n)
+	}
+	mean := n / distShards
+	for i, s := range sm.table.Load().shards {
+		if l := len(s.data); l < mean/2 || l > mean*3/2 {
+			t.Fatalf("shard %d holds %d keys, mean %d", i, l, mean)
+		}
+	}
+}
+
+func TestShardedMapDistribution(t *testing.T) {
+	t.Run("int", func(t *testing.T) { checkDistribution(t, intKey) })
+	t.Run("string", func(t *testing.T) { checkDistribution(t, stringKey) })
+	t.Run("struct", func(t *testing.T) { checkDistribution(t, structKeyOf) })
+	t.Run("array", func(t *testing.T) { checkDistribution(t, arrayKeyOf) })
+}
+
+// TestShardedMapStructKeyEquality 内容相同但字符串底层指针不同的 key 必须落在同一分片并命中
+func TestShardedMapStructKeyEquality(t *testing.T) {
+	sm := NewShardedMap[structKey, int](distShards)
+	for i := range 1000 {
+		sm.Set(structKeyOf(i), i)
+	}
+	for i := range 1000 {
+		k := structKeyOf(i)
+		k.Name = string([]byte(k.Name)) // 新分配的字符串
+		if v, ok := sm.Get(k); !ok || v != i {
+			t.Fatalf("Get(%v) = %d, %v, want %d, true", k, v, ok, i)
+		}
+	}
+}
+
+func TestShardedMapCustomHasher(t *testing.T) {
+	sm := NewShardedMapWithHasher[int, int](4, func(key int) uint64 { return uint64(key) })
+	for i := range 8 {
+		sm.Set(i, i)
+	}
+	for i, s := range sm.table.Load().shards {
+		if _, ok := s.data[i]; !ok || len(s.data) != 2 {
+			t.Fatalf("shard %d = %v, want keys %d and %d", i, s.data, i, i+4)
+		}
+	}
+}
+
+// ═══════════════════════════════════════════════════════
+// 在线扩缩容
+// ═══════════════════════════════════════════════════════
+
+// waitResize 等待迁移完成
+func waitResize[K comparable, V any](t *testing.T, sm *ShardedMap[K, V]) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for sm.ResizeStatus().Resizing {
+		if time.Now().After(deadline) {
+			t.Fatalf("resize did not finish: %+v", sm.ResizeStatus())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// beginResize 装上新分片表但不启动后台迁移,用于构造确定的迁移中间状态
+func beginResize[K comparable, V any](sm *ShardedMap[K, V], n int) *shardTable[K, V] {
+	next := &shardTable[K, V]{shards: newShards[K, V](n), prev: sm.table.Load().shards}
+	sm.table.Store(next)
+	return next
+}
+
+// checkExactlyOnce 要求 Range 恰好输出 want 中的每个 key 一次(允许出现 want 之外的 key)
+func checkExactlyOnce(t *testing.T, sm *ShardedMap[int, int], want func(k int) bool, n int) {
+	t.Helper()
+	counts := make(map[int]int)
+	sm.Range(func(k, v int) bool {
+		counts[k]++
+		return true
+	})
+	for k, c := range counts {
+		if c != 1 {
+			t.Fatalf("Range yielded key %d %d times", k, c)
+		}
+	}
+	for k := range n {
+		if want(k) && counts[k] != 1 {
+			t.Fatalf("Range missed key %d", k)
+		}
+	}
+}
+
+// TestShardedMapResizeConcurrent 迁移期间并发 Set / Delete / Get / Range:
+// 稳定 key 始终可读且 Range 中恰好出现一次,各写者自己的 key 最终与其模型一致
+func TestShardedMapResizeConcurrent(t *testing.T) {
+	const (
+		stable  = 20_000
+		writers = 4
+		perW    = 2_000
+	)
+	for _, sizes := range [][2]int{{1, 64}, {16, 3}, {4, 4 * 1024}} {
+		t.Run(fmt.Sprintf("%d->%d", sizes[0], sizes[1]), func(t *testing.T) {
+			sm := NewShardedMap[int, int](sizes[0])
+			for i := range stable {
+				sm.Set(i, i)
+			}
+
+			stop := make(chan struct{})
+			var wg sync.WaitGroup
+			models := make([]map[int]int, writers)
+			for w := range writers {
+				models[w] = make(map[int]int)
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					rng := rand.New(rand.NewPCG(uint64(w), 0))
+					base := stable + w*perW
+					for i := 0; ; i++ {
+						select {
+						case <-stop:
+							return
+						default:
+						}
+						k := base + rng.IntN(perW)
+						if rng.IntN(3) == 0 {
+							sm.Delete(k)
+							delete(models[w], k)
+						} else {
+							sm.Set(k, i)
+							models[w][k] = i
+						}
+					}
+				}(w)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; ; i++ {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					k := i % stable
+					if v, ok := sm.Get(k); !ok || v != k {
+						t.Errorf("Get(%d) = %d, %v during resize", k, v, ok)
+						return
+					}
+				}
+			}()
+
+			if err := sm.Resize(sizes[1]); err != nil {
+				t.Fatal(err)
+			}
+			for sm.ResizeStatus().Resizing {
+				checkExactlyOnce(t, sm, func(k int) bool { return k < stable }, stable)
+			}
+			close(stop)
+			wg.Wait()
+			waitResize(t, sm)
+
+			if sm.ShardCount() != sizes[1] {
+				t.Fatalf("ShardCount = %d, want %d", sm.ShardCount(), sizes[1])
+			}
+			want := stable
+			for _, m := range models {
+				want += len(m)
+				for k, v := range m {
+					if got, ok := sm.Get(k); !ok || got != v {
+						t.Fatalf("Get(%d) = %d, %v, want %d", k, got, ok, v)
+					}
+				}
+			}
+			if sm.Len() != want {
+				t.Fatalf("Len = %d, want %d", sm.Len(), want)
+			}
+			checkExactlyOnce(t, sm, func(k int) bool { return true }, 0)
+			if len(sm.Snapshot()) != want {
+				t.Fatalf("len(Snapshot) = %d, want %d", len(sm.Snapshot()), want)
+			}
+		})
+	}
+}
+
+// TestShardedMapMidMigration 在确定的迁移中间状态下检查读写、Snapshot、Len、Range、Clear 与进度
+func TestShardedMapMidMigration(t *testing.T) {
+	const n = 10_000
+	sm := NewShardedMap[int, int](4)
+	for i := range n {
+		sm.Set(i, i)
+	}
+	next := beginResize(sm, 16)
+
+	// 第一个旧分片完整迁移,第二个只迁移一批
+	next.prev[0].mu.Lock()
+	sm.migrate_step(next, next.prev[0], n)
+	next.prev[0].mu.Unlock()
+	next.prev[1].mu.Lock()
+	sm.migrate_step(next, next.prev[1], 100)
+	next.prev[1].mu.Unlock()
+
+	st := sm.ResizeStatus()
+	if !st.Resizing || st.From != 4 || st.To != 16 || st.Migrated != 1 || st.Progress() != 0.25 {
+		t.Fatalf("ResizeStatus = %+v, Progress %v", st, st.Progress())
+	}
+
+	// 写入迁移中的 key:新值可读,且不会在新旧分片中各留一份
+	for i := 0; i < n; i += 7 {
+		sm.Set(i, -i)
+	}
+	for i := range n {
+		want := i
+		if i%7 == 0 {
+			want = -i
+		}
+		if v, ok := sm.Get(i); !ok || v != want {
+			t.Fatalf("Get(%d) = %d, %v, want %d", i, v, ok, want)
+		}
+	}
+	if sm.Len() != n {
+		t.Fatalf("Len = %d, want %d", sm.Len(), n)
+	}
+	snap := sm.Snapshot()
+	if len(snap) != n || snap[7] != -7 || snap[8] != 8 {
+		t.Fatalf("Snapshot has %d entries, [7]=%d [8]=%d", len(snap), snap[7], snap[8])
+	}
+	checkExactlyOnce(t, sm, func(k int) bool { return true }, n)
+
+	if err := sm.Resize(8); err == nil {
+		t.Fatal("Resize during migration: err = nil")
+	}
+
+	sm.Clear()
+	if sm.Len() != 0 || len(sm.Snapshot()) != 0 {
+		t.Fatalf("after Clear: Len = %d", sm.Len())
+	}
+	sm.Set(1, 1)
+
+	sm.migrate(next)
+	if st := sm.ResizeStatus(); st.Resizing || st.Progress() != 1 || st.To != 16 {
+		t.Fatalf("ResizeStatus after migrate = %+v", st)
+	}
+	if v, ok := sm.Get(1); !ok || v != 1 || sm.Len() != 1 {
+		t.Fatalf("Get(1) = %d, %v, Len = %d", v, ok, sm.Len())
+	}
+}
+
+// TestShardedMapResizeProgress 进度单调推进并最终到达 1
+func TestShardedMapResizeProgress(t *testing.T) {
+	sm := NewShardedMap[int, int](8)
+	if p := sm.ResizeStatus().Progress(); p != 1 {
+		t.Fatalf("Progress when idle = %v, want 1", p)
+	}
+	for i := range 50_000 {
+		sm.Set(i, i)
+	}
+	if err := sm.Resize(0); err == nil {
+		t.Fatal("Resize(0): err = nil")
+	}
+	if err := sm.Resize(8); err != nil || sm.ResizeStatus().Resizing {
+		t.Fatalf("Resize to the same count: err = %v, status %+v", err, sm.ResizeStatus())
+	}
+
+	if err := sm.Resize(2); err != nil {
+		t.Fatal(err)
+	}
+	last := 0.0
+	for sm.ResizeStatus().Resizing {
+		p := sm.ResizeStatus().Progress()
+		if p < last || p > 1 {
+			t.Fatalf("Progress went from %v to %v", last, p)
+		}
+		last = p
+	}
+	st := sm.ResizeStatus()
+	if st.Progress() != 1 || st.From != 2 || st.To != 2 || sm.ShardCount() != 2 {
+		t.Fatalf("ResizeStatus after shrink = %+v", st)
+	}
+	if sm.Len() != 50_000 {
+		t.Fatalf("Len = %d, want 50000", sm.Len())
+	}
+}
+
+// TestShardedMapAutoResize 平均分片大小超过阈值后自动扩容,且不超过 MaxShards
+func TestShardedMapAutoResize(t *testing.T) {
+	sm := NewShardedMap[int, int](4)
+	sm.SetAutoResize(&AutoResizePolicy{MaxAvgShardSize: 100})
+	for i := range 400 {
+		sm.Set(i, i)
+	}
+	waitResize(t, sm)
+	if sm.ShardCount() != 4 {
+		t.Fatalf("ShardCount = %d before exceeding the threshold, want 4", sm.ShardCount())
+	}
+
+	for i := 400; i < 4_000; i++ {
+		sm.Set(i, i)
+		waitResize(t, sm)
+	}
+	if c := sm.ShardCount(); c <= 4 || sm.Len()/c > 100 {
+		t.Fatalf("ShardCount = %d for %d entries, want avg <= 100", c, sm.Len())
+	}
+
+	capped := NewShardedMap[int, int](2)
+	capped.SetAutoResize(&AutoResizePolicy{MaxAvgShardSize: 10, Factor: 4, MaxShards: 8})
+	for i := range 2_000 {
+		capped.Set(i, i)
+		waitResize(t, capped)
+	}
+	if c := capped.ShardCount(); c != 8 {
+		t.Fatalf("ShardCount = %d, want MaxShards 8", c)
+	}
+
+	off := NewShardedMap[int, int](2)
+	off.SetAutoResize(&AutoResizePolicy{MaxAvgShardSize: 10})
+	off.SetAutoResize(nil)
+	for i := range 1_000 {
+		off.Set(i, i)
+	}
+	if off.ResizeStatus().Resizing || off.ShardCount() != 2 {
+		t.Fatalf("auto resize still active after SetAutoResize(nil): %+v", off.ResizeStatus())
+	}
+	if sm.Len() != 4_000 || capped.Len() != 2_000 {
+		t.Fatalf("Len = %d / %d after auto resize", sm.Len(), capped.Len())
+	}
+}
+
+// ═══════════════════════════════════════════════════════
+// Benchmark:不同 key 类型的 Set / Get
+// ═══════════════════════════════════════════════════════
+
+func benchSetGet[K comparable](b *testing.B, key func(int) K) {
+	const n = 1 << 14
+	keys := make([]K, n)
+	for i := range keys {
+		keys[i] = key(i)
+	}
+	sm := NewShardedMap[K, int](distShards)
+
+	b.Run("Set", func(b *testing.B) {
+		i := 0
+		for b.Loop() {
+			sm.Set(keys[i&(n-1)], i)
+			i++
+		}
+	})
+	b.Run("Get", func(b *testing.B) {
+		i := 0
+		for b.Loop() {
+			sm.Get(keys[i&(n-1)])
+			i++
+		}
+	})
+	b.Run("ParallelGet", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				sm.Get(keys[i&(n-1)])
+				i++
+			}
+		})
+	})
+}
+
+func BenchmarkShardedMap_Int(b *testing.B)    { benchSetGet(b, intKey) }
+func BenchmarkShardedMap_String(b *testing.B) { benchSetGet(b, stringKey) }
+func BenchmarkShardedMap_Struct(b *testing.B) { benchSetGet(b, structKeyOf) }
+func BenchmarkShardedMap_Array(b *testing.B)  { benchSetGet(b, arrayKeyOf) }
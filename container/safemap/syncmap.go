@@ -0,0 +1,75 @@
+package safemap
+
+import "sync"
+
+// Map 是 *sync.Map 的方法集合;*sync.Map 与 *SyncMap 都实现了它,
+// 业务代码依赖 Map 接口即可在两者之间无缝切换。
+type Map interface {
+	Load(key any) (value any, ok bool)
+	Store(key, value any)
+	LoadOrStore(key, value any) (actual any, loaded bool)
+	LoadAndDelete(key any) (value any, loaded bool)
+	Delete(key any)
+	Swap(key, value any) (previous any, loaded bool)
+	CompareAndSwap(key, old, new any) (swapped bool)
+	CompareAndDelete(key, old any) (deleted bool)
+	Range(f func(key, value any) bool)
+	Clear()
+}
+
+var (
+	_ Map = (*sync.Map)(nil)
+	_ Map = (*SyncMap)(nil)
+)
+
+// SyncMap 是基于 ShardedMap[any, any] 的 sync.Map 替代品,适合写多或 key 集合频繁变化的场景。
+// 与 sync.Map 相同:key 的动态类型必须可比较,CompareAndSwap / CompareAndDelete 的 old 也必须可比较,否则 panic。
+// Range 是弱一致的,见 ShardedMap 的说明。
+type SyncMap struct {
+	m *ShardedMap[any, any]
+}
+
+// NewSyncMap 创建 SyncMap,shardCount <= 0 时取 32。
+func NewSyncMap(shardCount int) *SyncMap {
+	return &SyncMap{m: NewShardedMap[any, any](shardCount)}
+}
+
+func (sm *SyncMap) Load(key any) (value any, ok bool) {
+	return sm.m.Get(key)
+}
+
+func (sm *SyncMap) Store(key, value any) {
+	sm.m.Set(key, value)
+}
+
+func (sm *SyncMap) LoadOrStore(key, value any) (actual any, loaded bool) {
+	return sm.m.GetOrStore(key, value)
+}
+
+func (sm *SyncMap) LoadAndDelete(key any) (value any, loaded bool) {
+	return sm.m.LoadAndDelete(key)
+}
+
+func (sm *SyncMap) Delete(key any) {
+	sm.m.Delete(key)
+}
+
+func (sm *SyncMap) Swap(key, value any) (previous any, loaded bool) {
+	return sm.m.Swap(key, value)
+}
+
+func (sm *SyncMap) CompareAndSwap(key, old, new any) (swapped bool) {
+	return sm.m.CompareAndSwap(key, old, new)
+}
+
+func (sm *SyncMap) CompareAndDelete(key, old any) (deleted bool) {
+	return sm.m.CompareAndDelete(key, old)
+}
+
+func (sm *SyncMap) Range(f func(key, value any) bool) {
+	sm.m.Range(f)
+}
+
+func (sm *SyncMap) Clear() {
+	sm.m.Clear()
+}
@@ -0,0 +1,182 @@
+package safeslice
+
+import (
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// COWSlice 是写时复制的并发切片:读操作原子地取得当前快照后直接读取,不加锁,也不会被写入阻塞;
+// 写操作在互斥锁内复制出新切片、修改后原子替换,代价为 O(n)。适合读多写少的场景,例如订阅者列表。
+//
+// 注意:Range / All 遍历的是开始时的快照,期间被删除的元素仍可能被遍历到。
+// 如果删除方随后会释放该元素(例如关闭 channel),需要自行保证与正在进行的遍历互斥。
+//
+// 与 SafeSlice 一样,零值即可直接使用。
+type COWSlice[T any] struct {
+	mu    sync.Mutex // 串行化写者
+	items atomic.Pointer[[]T]
+}
+
+func NewCOWSlice[T any](items ...T) *COWSlice[T] {
+	ret := &COWSlice[T]{}
+	snapshot := slices.Clone(items)
+	ret.items.Store(&snapshot)
+	return ret
+}
+
+// load 返回当前快照,调用方不得修改;零值 COWSlice 尚未发布快照时返回 nil,写操作从空切片开始
+func (l *COWSlice[T]) load() []T {
+	p := l.items.Load()
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// store 发布新快照,调用方必须持有 mu 且之后不再修改 items
+func (l *COWSlice[T]) store(items []T) {
+	l.items.Store(&items)
+}
+
+// Peek 查看但不取出
+func (l *COWSlice[T]) Peek(index int) (T, bool) {
+	items := l.load()
+	if index < 0 || index >= len(items) {
+		var zero T
+		return zero, false
+	}
+	return items[index], true
+}
+
+// PeekFirst 查看队首
+func (l *COWSlice[T]) PeekFirst() (T, bool) {
+	return l.Peek(0)
+}
+
+// PeekLast 查看队尾
+func (l *COWSlice[T]) PeekLast() (T, bool) {
+	items := l.load()
+	if len(items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return items[len(items)-1], true
+}
+
+// Range 遍历快照(无锁,f 内可以写入本 COWSlice,但不影响本次遍历)
+func (l *COWSlice[T]) Range(f func(index int, item T) bool) {
+	for i, item := range l.load() {
+		if !f(i, item) {
+			return
+		}
+	}
+}
+
+// All 以 iter.Seq2 的形式遍历快照(无锁)
+func (l *COWSlice[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, item := range l.load() {
+			if !yield(i, item) {
+				return
+			}
+		}
+	}
+}
+
+// IndexFunc 返回第一个满足 predicate 的下标,没有时返回 -1
+func (l *COWSlice[T]) IndexFunc(predicate func(T) bool) int {
+	return slices.IndexFunc(l.load(), predicate)
+}
+
+// Snapshot 返回当前内容的副本
+func (l *COWSlice[T]) Snapshot() []T {
+	return slices.Clone(l.load())
+}
+
+// Len 长度
+func (l *COWSlice[T]) Len() int {
+	return len(l.load())
+}
+
+// Append 追加
+func (l *COWSlice[T]) Append(item T) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := l.load()
+	l.store(append(old[:len(old):len(old)], item))
+}
+
+// Set 覆盖 index 处的元素,越界时返回 false
+func (l *COWSlice[T]) Set(index int, item T) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := l.load()
+	if index < 0 || index >= len(old) {
+		return false
+	}
+	items := slices.Clone(old)
+	items[index] = item
+	l.store(items)
+	return true
+}
+
+// Insert 在 index 处插入元素,index == Len() 时等同于 Append;越界时返回 false
+func (l *COWSlice[T]) Insert(index int, item T) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := l.load()
+	if index < 0 || index > len(old) {
+		return false
+	}
+	items := make([]T, 0, len(old)+1)
+	items = append(items, old[:index]...)
+	items = append(items, item)
+	items = append(items, old[index:]...)
+	l.store(items)
+	return true
+}
+
+// RemoveAt 删除并返回 index 处的元素,越界时返回 false
+func (l *COWSlice[T]) RemoveAt(index int) (T, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := l.load()
+	if index < 0 || index >= len(old) {
+		var zero T
+		return zero, false
+	}
+	items := make([]T, 0, len(old)-1)
+	items = append(items, old[:index]...)
+	items = append(items, old[index+1:]...)
+	l.store(items)
+	return old[index], true
+}
+
+// RemoveIf 按条件删除,返回删除的个数;没有元素被删除时不发布新快照
+func (l *COWSlice[T]) RemoveIf(predicate func(T) bool) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := l.load()
+	items := make([]T, 0, len(old))
+	for _, item := range old {
+		if !predicate(item) {
+			items = append(items, item)
+		}
+	}
+	removed := len(old) - len(items)
+	if removed > 0 {
+		l.store(items)
+	}
+	return removed
+}
+
+// Sort 按 cmp 稳定排序
+func (l *COWSlice[T]) Sort(cmp func(a, b T) int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	items := slices.Clone(l.load())
+	slices.SortStableFunc(items, cmp)
+	l.store(items)
+}
@@ -1,6 +1,35 @@
 package safeslice
 
-import "sync"
+import (
+	"iter"
+	"slices"
+	"sync"
+)
+
+// Slice 是 SafeSlice 与 COWSlice 的公共方法集合。
+// 读多写少(如订阅者列表)时用 COWSlice,读者无锁;写入频繁时用 SafeSlice。
+type Slice[T any] interface {
+	Peek(index int) (T, bool)
+	PeekFirst() (T, bool)
+	PeekLast() (T, bool)
+	Range(f func(index int, item T) bool)
+	All() iter.Seq2[int, T]
+	IndexFunc(predicate func(T) bool) int
+	Snapshot() []T
+	Len() int
+
+	Append(item T)
+	Set(index int, item T) bool
+	Insert(index int, item T) bool
+	RemoveAt(index int) (T, bool)
+	RemoveIf(predicate func(T) bool) int
+	Sort(cmp func(a, b T) int)
+}
+
+var (
+	_ Slice[int] = (*SafeSlice[int])(nil)
+	_ Slice[int] = (*COWSlice[int])(nil)
+)
 
 type SafeSlice[T any] struct {
 	mu    sync.RWMutex
@@ -94,3 +123,72 @@ func (l *SafeSlice[T]) Len() int {
 	defer l.mu.RUnlock()
 	return len(l.items)
 }
+
+// All 以 iter.Seq2 的形式遍历(迭代期间持有读锁,循环体内不能写入本 SafeSlice)
+func (l *SafeSlice[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		l.mu.RLock()
+		defer l.mu.RUnlock()
+		for i, item := range l.items {
+			if !yield(i, item) {
+				return
+			}
+		}
+	}
+}
+
+// IndexFunc 返回第一个满足 predicate 的下标,没有时返回 -1
+func (l *SafeSlice[T]) IndexFunc(predicate func(T) bool) int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return slices.IndexFunc(l.items, predicate)
+}
+
+// Snapshot 返回当前内容的副本
+func (l *SafeSlice[T]) Snapshot() []T {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return slices.Clone(l.items)
+}
+
+// Set 覆盖 index 处的元素,越界时返回 false
+func (l *SafeSlice[T]) Set(index int, item T) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if index < 0 || index >= len(l.items) {
+		return false
+	}
+	l.items[index] = item
+	return true
+}
+
+// Insert 在 index 处插入元素,index == Len() 时等同于 Append;越界时返回 false
+func (l *SafeSlice[T]) Insert(index int, item T) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if index < 0 || index > len(l.items) {
+		return false
+	}
+	l.items = slices.Insert(l.items, index, item)
+	return true
+}
+
+// RemoveAt 删除并返回 index 处的元素,越界时返回 false
+func (l *SafeSlice[T]) RemoveAt(index int) (T, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if index < 0 || index >= len(l.items) {
+		var zero T
+		return zero, false
+	}
+	item := l.items[index]
+	l.items = slices.Delete(l.items, index, index+1)
+	return item, true
+}
+
+// Sort 按 cmp 稳定排序
+func (l *SafeSlice[T]) Sort(cmp func(a, b T) int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slices.SortStableFunc(l.items, cmp)
+}
@@ -0,0 +1,476 @@
+package skiplist
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// snapshotRetries 迭代类操作乐观重试的次数，超过后退化为短暂冻结写者
+const snapshotRetries = 8
+
+// markedRef 带删除标记的前向引用（不可变）
+// 对应 Java AtomicMarkableReference：next 指针与 marked 标记作为一个整体做 CAS
+type markedRef[T any] struct {
+	node   *ConcurrentNode[T] // 后继节点，nil 表示到达末尾
+	marked bool               // 持有该引用的节点在这一层已被逻辑删除
+}
+
+// ConcurrentNode 无锁跳表节点
+type ConcurrentNode[T any] struct {
+	value T                              // 节点值
+	next  []atomic.Pointer[markedRef[T]] // 每一层的前向引用
+}
+
+// Value 返回节点值
+func (n *ConcurrentNode[T]) Value() T {
+	return n.value
+}
+
+// load 读取第 level 层的后继与标记
+func (n *ConcurrentNode[T]) load(level int) (*ConcurrentNode[T], bool) {
+	ref := n.next[level].Load()
+	return ref.node, ref.marked
+}
+
+// cas 当第 level 层的引用仍为 (expectNode, expectMark) 时，原子替换为 (newNode, newMark)
+func (n *ConcurrentNode[T]) cas(level int, expectNode *ConcurrentNode[T], expectMark bool, newNode *ConcurrentNode[T], newMark bool) bool {
+	old := n.next[level].Load()
+	if old.node != expectNode || old.marked != expectMark {
+		return false
+	}
+	if old.node == newNode && old.marked == newMark {
+		return true
+	}
+	return n.next[level].CompareAndSwap(old, &markedRef[T]{node: newNode, marked: newMark})
+}
+
+// ConcurrentSkipList 无锁并发跳表
+// 采用 Herlihy / Lev / Luchangco / Shavit 的 lock-free 跳表设计：
+//   - 插入：先在第 0 层 CAS 链入（线性化点），再自底向上逐层链入
+//   - 删除：先自顶向下给各层前向引用打删除标记，第 0 层打标记成功即为线性化点，
+//     之后由 find 在遍历时顺手把已标记节点物理摘除
+//   - Search / Contains：wait-free，只跳过已标记节点，不做任何写操作
+//
+// 迭代类操作（GetAll / RangeQuery / GetMin / GetMax）通过「双重采集」保证线性一致：
+// 遍历前后都确认期间没有写操作发生才返回；连续 snapshotRetries 次失败后，
+// 会短暂冻结新的写者，等进行中的写操作结束后完成一次遍历。
+// 因此写者在常态下无锁，只有在迭代者饥饿时才会短暂让步。
+//
+// API 与 SkipList 保持一致（去重插入、按 better 排序）；
+// 不维护跨度信息，因此不提供 Rank / ByRank 等排名操作。
+type ConcurrentSkipList[T any] struct {
+	head        atomic.Pointer[ConcurrentNode[T]] // 头节点（哨兵），Clear 时整体替换
+	better      func(a, b T) bool                 // 比较函数：返回 true 表示 a 排在 b 前面
+	maxLevel    int                               // 最大层数
+	probability float64                           // 晋升概率
+	length      atomic.Int64                      // 元素数量
+
+	started  atomic.Uint64 // 已开始的写操作数
+	finished atomic.Uint64 // 已结束的写操作数
+	frozen   atomic.Bool   // 为 true 时新的写者需等待
+	freezeMu sync.Mutex    // 串行化需要冻结写者的操作
+}
+
+// ═══════════════════════════════════════════════════════
+// 构造函数
+// ═══════════════════════════════════════════════════════
+
+// NewConcurrent 创建新的无锁并发跳表
+// better: 比较函数，返回 true 表示 a 排在 b 前面（即 a 更小/优先级更高）
+func NewConcurrent[T any](better func(a, b T) bool) *ConcurrentSkipList[T] {
+	return NewConcurrentWithConfig(better, DefaultMaxLevel, DefaultProbability)
+}
+
+// NewConcurrentWithConfig 使用自定义配置创建无锁并发跳表
+func NewConcurrentWithConfig[T any](better func(a, b T) bool, maxLevel int, probability float64) *ConcurrentSkipList[T] {
+	sl := &ConcurrentSkipList[T]{
+		better:      better,
+		maxLevel:    maxLevel,
+		probability: probability,
+	}
+	sl.head.Store(sl.createNode(*new(T), maxLevel))
+	return sl
+}
+
+// ═══════════════════════════════════════════════════════
+// 辅助方法
+// ═══════════════════════════════════════════════════════
+
+// randomLevel 随机生成节点层数（math/rand/v2 的全局函数并发安全）
+func (sl *ConcurrentSkipList[T]) randomLevel() int {
+	level := 1
+	for rand.Float64() < sl.probability && level < sl.maxLevel {
+		level++
+	}
+	return level
+}
+
+// createNode 创建新节点，各层前向引用初始化为 (nil, false)
+func (sl *ConcurrentSkipList[T]) createNode(value T, level int) *ConcurrentNode[T] {
+	n := &ConcurrentNode[T]{
+		value: value,
+		next:  make([]atomic.Pointer[markedRef[T]], level),
+	}
+	for i := range n.next {
+		n.next[i].Store(&markedRef[T]{})
+	}
+	return n
+}
+
+// equal 两个值按 better 判定是否相等
+func (sl *ConcurrentSkipList[T]) equal(a, b T) bool {
+	return !sl.better(a, b) && !sl.better(b, a)
+}
+
+// beginWrite 登记一次写操作；冻结期间等待
+func (sl *ConcurrentSkipList[T]) beginWrite() {
+	for {
+		for sl.frozen.Load() {
+			runtime.Gosched()
+		}
+		sl.started.Add(1)
+		if !sl.frozen.Load() {
+			return
+		}
+		// 登记后才发现被冻结：撤销登记，继续等待
+		sl.finished.Add(1)
+	}
+}
+
+// endWrite 结束一次写操作
+func (sl *ConcurrentSkipList[T]) endWrite() {
+	sl.finished.Add(1)
+}
+
+// quiescent 判断此刻是否没有进行中的写操作
+// 先读 finished 再读 started：finished ≤ started 恒成立，两者相等说明读 started 时无写者
+func (sl *ConcurrentSkipList[T]) quiescent() (uint64, bool) {
+	f := sl.finished.Load()
+	s := sl.started.Load()
+	return s, s == f
+}
+
+// exclusive 冻结新的写者，等进行中的写操作结束后执行 fn
+func (sl *ConcurrentSkipList[T]) exclusive(fn func()) {
+	sl.freezeMu.Lock()
+	defer sl.freezeMu.Unlock()
+
+	sl.frozen.Store(true)
+	defer sl.frozen.Store(false)
+
+	for _, ok := sl.quiescent(); !ok; _, ok = sl.quiescent() {
+		runtime.Gosched()
+	}
+	fn()
+}
+
+// consistentRead 在一个没有写操作的时间窗口内执行 read，保证读到的是某一时刻的完整状态
+// read 可能被执行多次，每次执行前需自行重置输出
+func (sl *ConcurrentSkipList[T]) consistentRead(read func(head *ConcurrentNode[T])) {
+	for range snapshotRetries {
+		before, ok := sl.quiescent()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		read(sl.head.Load())
+		if sl.started.Load() == before {
+			return
+		}
+	}
+
+	// 乐观重试失败，短暂冻结写者
+	sl.exclusive(func() {
+		read(sl.head.Load())
+	})
+}
+
+// find 找到 value 在每一层的前驱与后继，遍历过程中摘除已标记的节点
+// 返回第 0 层后继是否等于 value
+func (sl *ConcurrentSkipList[T]) find(head *ConcurrentNode[T], value T, preds, succs []*ConcurrentNode[T]) bool {
+retry:
+	pred := head
+	var curr *ConcurrentNode[T]
+	for i := sl.maxLevel - 1; i >= 0; i-- {
+		curr, _ = pred.load(i)
+		for curr != nil {
+			succ, marked := curr.load(i)
+			// 摘除 curr 在这一层的链接
+			for marked {
+				if !pred.cas(i, curr, false, succ, false) {
+					goto retry
+				}
+				curr, _ = pred.load(i)
+				if curr == nil {
+					break
+				}
+				succ, marked = curr.load(i)
+			}
+			if curr == nil || !sl.better(curr.value, value) {
+				break
+			}
+			pred = curr
+			curr = succ
+		}
+		preds[i] = pred
+		succs[i] = curr
+	}
+	return curr != nil && sl.equal(curr.value, value)
+}
+
+// ═══════════════════════════════════════════════════════
+// 核心操作
+// ═══════════════════════════════════════════════════════
+
+// Insert 插入元素（lock-free）；已存在相等元素时不重复插入
+func (sl *ConcurrentSkipList[T]) Insert(value T) {
+	sl.beginWrite()
+	defer sl.endWrite()
+
+	head := sl.head.Load()
+	preds := make([]*ConcurrentNode[T], sl.maxLevel)
+	succs := make([]*ConcurrentNode[T], sl.maxLevel)
+	topLevel := sl.randomLevel()
+
+	for {
+		if sl.find(head, value, preds, succs) {
+			return // 已存在，不重复插入
+		}
+
+		newNode := sl.createNode(value, topLevel)
+		for i := range topLevel {
+			newNode.next[i].Store(&markedRef[T]{node: succs[i]})
+		}
+
+		// 线性化点：第 0 层链入
+		if !preds[0].cas(0, succs[0], false, newNode, false) {
+			continue
+		}
+		sl.length.Add(1)
+
+		// 自底向上逐层链入
+		for i := 1; i < topLevel; i++ {
+			for {
+				// 链入前先修正新节点在这一层的后继；若已被并发删除则停止链入
+				next, marked := newNode.load(i)
+				if marked {
+					return
+				}
+				if next != succs[i] && !newNode.cas(i, next, false, succs[i], false) {
+					return
+				}
+				if preds[i].cas(i, succs[i], false, newNode, false) {
+					break
+				}
+				if !sl.find(head, value, preds, succs) || succs[0] != newNode {
+					return // 新节点已被删除
+				}
+			}
+		}
+		return
+	}
+}
+
+// Search 查找元素（wait-free）；不存在返回 nil
+func (sl *ConcurrentSkipList[T]) Search(value T) *ConcurrentNode[T] {
+	pred := sl.head.Load()
+	var curr *ConcurrentNode[T]
+	for i := sl.maxLevel - 1; i >= 0; i-- {
+		curr, _ = pred.load(i)
+		for curr != nil {
+			succ, marked := curr.load(i)
+			// 跳过已标记节点，不做摘除
+			for marked {
+				curr = succ
+				if curr == nil {
+					break
+				}
+				succ, marked = curr.load(i)
+			}
+			if curr == nil || !sl.better(curr.value, value) {
+				break
+			}
+			pred = curr
+			curr = succ
+		}
+	}
+
+	if curr != nil && sl.equal(curr.value, value) {
+		return curr
+	}
+	return nil
+}
+
+// Delete 删除元素（lock-free）
+func (sl *ConcurrentSkipList[T]) Delete(value T) bool {
+	sl.beginWrite()
+	defer sl.endWrite()
+
+	head := sl.head.Load()
+	preds := make([]*ConcurrentNode[T], sl.maxLevel)
+	succs := make([]*ConcurrentNode[T], sl.maxLevel)
+
+	if !sl.find(head, value, preds, succs) {
+		return false
+	}
+	target := succs[0]
+
+	// 自顶向下给高层打删除标记
+	for i := len(target.next) - 1; i >= 1; i-- {
+		succ, marked := target.load(i)
+		for !marked {
+			target.cas(i, succ, false, succ, true)
+			succ, marked = target.load(i)
+		}
+	}
+
+	// 线性化点：第 0 层打标记成功
+	succ, _ := target.load(0)
+	for {
+		iMarkedIt := target.cas(0, succ, false, succ, true)
+		var marked bool
+		succ, marked = target.load(0)
+		if iMarkedIt {
+			sl.length.Add(-1)
+			sl.find(head, value, preds, succs) // 物理摘除
+			return true
+		}
+		if marked {
+			return false // 被其他删除者抢先
+		}
+	}
+}
+
+// ═══════════════════════════════════════════════════════
+// 查询操作
+// ═══════════════════════════════════════════════════════
+
+// RangeQuery 范围查询（线性一致）
+// 返回 [min, max] 范围内的所有元素
+func (sl *ConcurrentSkipList[T]) RangeQuery(min, max T) []T {
+	var result []T
+	sl.consistentRead(func(head *ConcurrentNode[T]) {
+		result = make([]T, 0)
+		for current := sl.firstUnmarked(sl.seek(head, min)); current != nil; current = sl.nextUnmarked(current) {
+			// 如果当前值已经超过 max，停止遍历
+			if sl.better(max, current.value) {
+				break
+			}
+			result = append(result, current.value)
+		}
+	})
+	return result
+}
+
+// GetMin 获取最小元素（线性一致）
+func (sl *ConcurrentSkipList[T]) GetMin() T {
+	var result T
+	sl.consistentRead(func(head *ConcurrentNode[T]) {
+		var zero T
+		result = zero
+		if first := sl.nextUnmarked(head); first != nil {
+			result = first.value
+		}
+	})
+	return result
+}
+
+// GetMax 获取最大元素（线性一致）
+func (sl *ConcurrentSkipList[T]) GetMax() T {
+	var result T
+	sl.consistentRead(func(head *ConcurrentNode[T]) {
+		// 从最高层开始向下找最后一个未标记节点
+		current := head
+		for i := sl.maxLevel - 1; i >= 0; i-- {
+			for next := sl.nextUnmarkedAt(current, i); next != nil; next = sl.nextUnmarkedAt(current, i) {
+				current = next
+			}
+		}
+		var zero T
+		result = zero
+		if current != head {
+			result = current.value
+		}
+	})
+	return result
+}
+
+// seek 返回第 0 层上第一个不排在 value 前面的节点（可能已标记）
+func (sl *ConcurrentSkipList[T]) seek(head *ConcurrentNode[T], value T) *ConcurrentNode[T] {
+	current := head
+	for i := sl.maxLevel - 1; i >= 0; i-- {
+		for next := sl.nextUnmarkedAt(current, i); next != nil && sl.better(next.value, value); next = sl.nextUnmarkedAt(current, i) {
+			current = next
+		}
+	}
+	next, _ := current.load(0)
+	return next
+}
+
+// nextUnmarkedAt 返回 n 在第 level 层之后第一个未在该层标记的节点
+func (sl *ConcurrentSkipList[T]) nextUnmarkedAt(n *ConcurrentNode[T], level int) *ConcurrentNode[T] {
+	next, _ := n.load(level)
+	for next != nil {
+		succ, marked := next.load(level)
+		if !marked {
+			return next
+		}
+		next = succ
+	}
+	return nil
+}
+
+// nextUnmarked 返回 n 在第 0 层之后第一个未删除的节点
+func (sl *ConcurrentSkipList[T]) nextUnmarked(n *ConcurrentNode[T]) *ConcurrentNode[T] {
+	return sl.nextUnmarkedAt(n, 0)
+}
+
+// firstUnmarked 返回从 n（含）开始第一个未删除的节点
+func (sl *ConcurrentSkipList[T]) firstUnmarked(n *ConcurrentNode[T]) *ConcurrentNode[T] {
+	for n != nil {
+		succ, marked := n.load(0)
+		if !marked {
+			return n
+		}
+		n = succ
+	}
+	return nil
+}
+
+// ═══════════════════════════════════════════════════════
+// 辅助操作
+// ═══════════════════════════════════════════════════════
+
+// Len 获取元素数量
+// 计数在各操作的线性化点之后更新，并发写入时可能短暂滞后
+func (sl *ConcurrentSkipList[T]) Len() int {
+	return int(sl.length.Load())
+}
+
+// Contains 检查元素是否存在（wait-free）
+func (sl *ConcurrentSkipList[T]) Contains(value T) bool {
+	return sl.Search(value) != nil
+}
+
+// GetAll 获取所有元素（按序，线性一致）
+func (sl *ConcurrentSkipList[T]) GetAll() []T {
+	var result []T
+	sl.consistentRead(func(head *ConcurrentNode[T]) {
+		result = make([]T, 0, sl.Len())
+		for current := sl.nextUnmarked(head); current != nil; current = sl.nextUnmarked(current) {
+			result = append(result, current.value)
+		}
+	})
+	return result
+}
+
+// Clear 清空跳表
+// 需要短暂冻结写者，等进行中的写操作结束后整体替换头节点
+func (sl *ConcurrentSkipList[T]) Clear() {
+	sl.exclusive(func() {
+		sl.head.Store(sl.createNode(*new(T), sl.maxLevel))
+		sl.length.Store(0)
+	})
+}
@@ -0,0 +1,191 @@
+package skiplist
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func intBetter(a, b int) bool { return a < b }
+
+// TestConcurrentSkipListParallel 多个 goroutine 各自负责互不相交的 key，并发插入/删除后校验最终状态
+func TestConcurrentSkipListParallel(t *testing.T) {
+	const workers = 8
+	const perWorker = 500
+
+	sl := NewConcurrent(intBetter)
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWorker {
+				key := i*workers + w
+				sl.Insert(key)
+				sl.Insert(key) // 重复插入应被忽略
+				if !sl.Contains(key) {
+					t.Errorf("key %d should be present after insert", key)
+				}
+				if key%3 == 0 && !sl.Delete(key) {
+					t.Errorf("delete %d should succeed", key)
+				}
+			}
+		}()
+	}
+
+	// 迭代者与写者并发运行，每次读到的都必须是有序、无重复的快照
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			all := sl.GetAll()
+			for i := 1; i < len(all); i++ {
+				if all[i-1] >= all[i] {
+					t.Errorf("snapshot not strictly ordered: %v >= %v", all[i-1], all[i])
+					return
+				}
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+
+	want := make([]int, 0)
+	for key := range workers * perWorker {
+		if key%3 != 0 {
+			want = append(want, key)
+		}
+	}
+	if got := sl.GetAll(); !slices.Equal(got, want) {
+		t.Fatalf("GetAll mismatch: got %d elements, want %d", len(got), len(want))
+	}
+	if sl.Len() != len(want) {
+		t.Fatalf("Len = %d, want %d", sl.Len(), len(want))
+	}
+	if got := sl.RangeQuery(10, 20); !slices.Equal(got, []int{10, 11, 13, 14, 16, 17, 19, 20}) {
+		t.Fatalf("RangeQuery(10, 20) = %v", got)
+	}
+	if sl.GetMin() != want[0] || sl.GetMax() != want[len(want)-1] {
+		t.Fatalf("GetMin/GetMax = %d/%d", sl.GetMin(), sl.GetMax())
+	}
+
+	sl.Clear()
+	if sl.Len() != 0 || len(sl.GetAll()) != 0 {
+		t.Fatal("Clear should empty the list")
+	}
+}
+
+// TestConcurrentSkipListContendedDelete 多个 goroutine 抢删同一批 key，每个 key 只能被删除一次
+func TestConcurrentSkipListContendedDelete(t *testing.T) {
+	const keys = 1000
+	const workers = 8
+
+	sl := NewConcurrent(intBetter)
+	for i := range keys {
+		sl.Insert(i)
+	}
+
+	var mu sync.Mutex
+	deleted := make(map[int]int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range keys {
+				if sl.Delete(i) {
+					mu.Lock()
+					deleted[i]++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(deleted) != keys {
+		t.Fatalf("deleted %d distinct keys, want %d", len(deleted), keys)
+	}
+	for k, n := range deleted {
+		if n != 1 {
+			t.Fatalf("key %d deleted %d times", k, n)
+		}
+	}
+	if sl.Len() != 0 || len(sl.GetAll()) != 0 {
+		t.Fatal("list should be empty")
+	}
+}
+
+// ═══════════════════════════════════════════════════════
+// 基准测试：无锁跳表 vs 读写锁跳表
+// ═══════════════════════════════════════════════════════
+
+const benchKeySpace = 1 << 16
+
+// skipListOps 两种跳表共同的操作集合
+type skipListOps interface {
+	Insert(int)
+	Delete(int) bool
+	Contains(int) bool
+}
+
+func prefill(sl skipListOps) {
+	for i := 0; i < benchKeySpace; i += 2 {
+		sl.Insert(i)
+	}
+}
+
+// runMixed readPercent% 的操作为 Contains，其余一半 Insert 一半 Delete
+func runMixed(b *testing.B, sl skipListOps, readPercent int) {
+	prefill(sl)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+		for pb.Next() {
+			key := r.IntN(benchKeySpace)
+			op := r.IntN(100)
+			switch {
+			case op < readPercent:
+				sl.Contains(key)
+			case op < readPercent+(100-readPercent)/2:
+				sl.Insert(key)
+			default:
+				sl.Delete(key)
+			}
+		}
+	})
+}
+
+func BenchmarkSkipListLocked_Read90(b *testing.B) {
+	runMixed(b, New(intBetter, true), 90)
+}
+
+func BenchmarkSkipListConcurrent_Read90(b *testing.B) {
+	runMixed(b, NewConcurrent(intBetter), 90)
+}
+
+func BenchmarkSkipListLocked_Read50(b *testing.B) {
+	runMixed(b, New(intBetter, true), 50)
+}
+
+func BenchmarkSkipListConcurrent_Read50(b *testing.B) {
+	runMixed(b, NewConcurrent(intBetter), 50)
+}
+
+func BenchmarkSkipListLocked_WriteOnly(b *testing.B) {
+	runMixed(b, New(intBetter, true), 0)
+}
+
+func BenchmarkSkipListConcurrent_WriteOnly(b *testing.B) {
+	runMixed(b, NewConcurrent(intBetter), 0)
+}
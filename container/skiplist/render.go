@@ -0,0 +1,82 @@
+package skiplist
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/leoheung/go-patterns/container/render"
+)
+
+// ═══════════════════════════════════════════════════════
+// 可视化
+// ═══════════════════════════════════════════════════════
+
+// ToGraph 把跳表的层级结构转换为 render.Graph(从左到右布局):
+//   - 头节点 head、尾部 NIL 与每个元素各对应一个节点,元素标签为「值 + 塔高」
+//   - 相邻两个节点之间在连续若干层上的前向指针合并为一条边,
+//     标签形如 "L0-L2 / 1",表示第 0~2 层都指向该节点、跨度为 1
+//
+// 读锁下完成快照(并发安全)。
+func (sl *SkipList[T]) ToGraph() *render.Graph {
+	if sl.mu != nil {
+		sl.mu.RLock()
+		defer sl.mu.RUnlock()
+	}
+
+	g := render.NewGraph("SkipList", render.LeftRight)
+	g.AddNode(render.Node{ID: "head", Label: "head", Shape: render.Box, Fill: "lightgrey"})
+
+	ids := map[*Node[T]]string{sl.head: "head"}
+	rank := 0
+	for cur := sl.head.level[0]; cur != nil; cur = cur.level[0] {
+		rank++
+		id := fmt.Sprintf("x%d", rank)
+		ids[cur] = id
+		g.AddNode(render.Node{
+			ID:    id,
+			Label: fmt.Sprintf("%v\nh=%d", cur.value, len(cur.level)),
+			Shape: render.Box,
+		})
+	}
+	g.AddNode(render.Node{ID: "tail", Label: "NIL", Shape: render.Box, Fill: "lightgrey"})
+
+	// 按第 0 层顺序遍历每个节点,把它在各层的前向指针按目标分组
+	for cur := sl.head; cur != nil; cur = cur.level[0] {
+		height := len(cur.level)
+		if cur == sl.head {
+			height = sl.level
+		}
+		for lo := 0; lo < height; {
+			next := cur.level[lo]
+			hi := lo
+			for hi+1 < height && cur.level[hi+1] == next {
+				hi++
+			}
+			to := "tail"
+			if next != nil {
+				to = ids[next]
+			}
+			levels := fmt.Sprintf("L%d", lo)
+			if hi > lo {
+				levels = fmt.Sprintf("L%d-L%d", lo, hi)
+			}
+			g.AddEdge(render.Edge{
+				From:  ids[cur],
+				To:    to,
+				Label: fmt.Sprintf("%s / %d", levels, cur.span[lo]),
+			})
+			lo = hi + 1
+		}
+	}
+	return g
+}
+
+// DrawDOT 以 Graphviz DOT 格式输出跳表的层级结构(并发安全)。
+func (sl *SkipList[T]) DrawDOT(out io.Writer) error {
+	return sl.ToGraph().WriteDOT(out)
+}
+
+// DrawMermaid 以 Mermaid flowchart 格式输出跳表的层级结构(并发安全)。
+func (sl *SkipList[T]) DrawMermaid(out io.Writer) error {
+	return sl.ToGraph().WriteMermaid(out)
+}
@@ -15,6 +15,7 @@ const (
 type Node[T any] struct {
 	value T          // 节点值
 	level []*Node[T] // 每一层的前向指针
+	span  []int      // 每一层前向指针跨越的第 0 层节点数（用于排名计算）
 }
 
 // SkipList 泛型跳表
@@ -57,9 +58,7 @@ func NewWithConfig[T any](better func(a, b T) bool, maxLevel int, probability fl
 	}
 
 	// 创建头节点，头节点的值不使用，但需要初始化所有层的前向指针
-	sl.head = &Node[T]{
-		level: make([]*Node[T], maxLevel),
-	}
+	sl.head = sl.createNode(*new(T), maxLevel)
 
 	return sl
 }
@@ -83,6 +82,7 @@ func (sl *SkipList[T]) createNode(value T, level int) *Node[T] {
 	return &Node[T]{
 		value: value,
 		level: make([]*Node[T], level),
+		span:  make([]int, level),
 	}
 }
 
@@ -102,22 +102,108 @@ func (sl *SkipList[T]) compare(a, b T) int {
 	return 0
 }
 
-// findPredecessors 找到每一层的前驱节点
+// findPredecessors 找到每一层的前驱节点，同时返回每个前驱节点的排名
 // 用于插入和删除操作
-func (sl *SkipList[T]) findPredecessors(value T) []*Node[T] {
+//
+// ranks[i] 表示 predecessors[i] 之前（含 predecessors[i] 自身）的第 0 层节点数，
+// 头节点的排名为 0
+func (sl *SkipList[T]) findPredecessors(value T) ([]*Node[T], []int) {
 	predecessors := make([]*Node[T], sl.maxLevel)
+	ranks := make([]int, sl.maxLevel)
 	current := sl.head
 
 	// 从最高层开始向下搜索
 	for i := sl.level - 1; i >= 0; i-- {
+		if i < sl.level-1 {
+			ranks[i] = ranks[i+1]
+		}
 		// 在当前层向右移动，直到找到合适的位置
 		for current.level[i] != nil && sl.better(current.level[i].value, value) {
+			ranks[i] += current.span[i]
 			current = current.level[i]
 		}
 		predecessors[i] = current
 	}
 
-	return predecessors
+	return predecessors, ranks
+}
+
+// deleteNode 从跳表中摘除节点 target，并修正各层跨度
+// predecessors 必须是 target 在每一层的前驱节点
+func (sl *SkipList[T]) deleteNode(target *Node[T], predecessors []*Node[T]) {
+	for i := 0; i < sl.level; i++ {
+		if predecessors[i].level[i] == target {
+			predecessors[i].span[i] += target.span[i] - 1
+			predecessors[i].level[i] = target.level[i]
+		} else {
+			predecessors[i].span[i]--
+		}
+	}
+
+	// 更新跳表的层数（如果最高层没有节点了）
+	for sl.level > 1 && sl.head.level[sl.level-1] == nil {
+		sl.level--
+	}
+
+	sl.length--
+}
+
+// nodeByRank 返回 0-based 排名为 rank 的节点；越界返回 nil
+func (sl *SkipList[T]) nodeByRank(rank int) *Node[T] {
+	if rank < 0 || rank >= sl.length {
+		return nil
+	}
+
+	target := rank + 1
+	traversed := 0
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.level[i] != nil && traversed+current.span[i] <= target {
+			traversed += current.span[i]
+			current = current.level[i]
+		}
+		if traversed == target {
+			return current
+		}
+	}
+
+	return nil
+}
+
+// lowerBound 返回第一个使 before 返回 false 的元素的 0-based 排名
+// before 必须对有序序列单调：一旦返回 false，之后的元素都返回 false
+// 所有元素都满足 before 时返回 sl.length
+func (sl *SkipList[T]) lowerBound(before func(T) bool) int {
+	rank := 0
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.level[i] != nil && before(current.level[i].value) {
+			rank += current.span[i]
+			current = current.level[i]
+		}
+	}
+	return rank
+}
+
+// normalizeRankRange 把 Redis 风格的闭区间 [start, stop] 规范化为合法的 0-based 下标
+// 负数表示从末尾倒数（-1 为最后一个元素）；区间为空时 ok 返回 false
+func normalizeRankRange(start, stop, length int) (int, int, bool) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop || start >= length {
+		return 0, 0, false
+	}
+	return start, stop, true
 }
 
 // ═══════════════════════════════════════════════════════
@@ -132,7 +218,7 @@ func (sl *SkipList[T]) Insert(value T) {
 	}
 
 	// 找到每一层的前驱节点
-	predecessors := sl.findPredecessors(value)
+	predecessors, ranks := sl.findPredecessors(value)
 
 	// 检查是否已存在（去重）
 	firstLevelPredecessor := predecessors[0]
@@ -146,9 +232,12 @@ func (sl *SkipList[T]) Insert(value T) {
 	newLevel := sl.randomLevel()
 
 	// 如果新节点的层数大于当前跳表的层数，需要更新跳表层数
+	// 新启用的层上头节点直接跨越到末尾
 	if newLevel > sl.level {
 		for i := sl.level; i < newLevel; i++ {
+			ranks[i] = 0
 			predecessors[i] = sl.head
+			predecessors[i].span[i] = sl.length
 		}
 		sl.level = newLevel
 	}
@@ -156,10 +245,18 @@ func (sl *SkipList[T]) Insert(value T) {
 	// 创建新节点
 	newNode := sl.createNode(value, newLevel)
 
-	// 在每一层插入新节点
+	// 在每一层插入新节点，并拆分前驱节点原有的跨度
 	for i := range newLevel {
 		newNode.level[i] = predecessors[i].level[i]
 		predecessors[i].level[i] = newNode
+
+		newNode.span[i] = predecessors[i].span[i] - (ranks[0] - ranks[i])
+		predecessors[i].span[i] = ranks[0] - ranks[i] + 1
+	}
+
+	// 新节点没有到达的更高层，跨度都增加 1
+	for i := newLevel; i < sl.level; i++ {
+		predecessors[i].span[i]++
 	}
 
 	sl.length++
@@ -201,7 +298,7 @@ func (sl *SkipList[T]) Delete(value T) bool {
 	}
 
 	// 找到每一层的前驱节点
-	predecessors := sl.findPredecessors(value)
+	predecessors, _ := sl.findPredecessors(value)
 
 	// 检查节点是否存在
 	target := predecessors[0].level[0]
@@ -210,18 +307,7 @@ func (sl *SkipList[T]) Delete(value T) bool {
 	}
 
 	// 在每一层删除节点
-	for i := 0; i < len(target.level); i++ {
-		if predecessors[i].level[i] == target {
-			predecessors[i].level[i] = target.level[i]
-		}
-	}
-
-	// 更新跳表的层数（如果最高层没有节点了）
-	for sl.level > 1 && sl.head.level[sl.level-1] == nil {
-		sl.level--
-	}
-
-	sl.length--
+	sl.deleteNode(target, predecessors)
 	return true
 }
 
@@ -301,6 +387,120 @@ func (sl *SkipList[T]) GetMax() T {
 	return current.value
 }
 
+// ═══════════════════════════════════════════════════════
+// 排名操作
+// ═══════════════════════════════════════════════════════
+
+// Rank 返回元素的 0-based 排名（并发安全），O(log n)
+// 元素不存在时返回 (-1, false)
+func (sl *SkipList[T]) Rank(value T) (int, bool) {
+	if sl.mu != nil {
+		sl.mu.RLock()
+		defer sl.mu.RUnlock()
+	}
+
+	rank := 0
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		// 与 findPredecessors 不同，这里允许走到等于 value 的节点上
+		for current.level[i] != nil && sl.compare(current.level[i].value, value) <= 0 {
+			rank += current.span[i]
+			current = current.level[i]
+		}
+		if current != sl.head && sl.compare(current.value, value) == 0 {
+			return rank - 1, true
+		}
+	}
+
+	return -1, false
+}
+
+// ByRank 返回 0-based 排名为 rank 的元素（并发安全），O(log n)
+// rank 越界返回 (zero, false)
+func (sl *SkipList[T]) ByRank(rank int) (T, bool) {
+	if sl.mu != nil {
+		sl.mu.RLock()
+		defer sl.mu.RUnlock()
+	}
+
+	var zero T
+	node := sl.nodeByRank(rank)
+	if node == nil {
+		return zero, false
+	}
+	return node.value, true
+}
+
+// RangeByRank 返回排名在闭区间 [start, stop] 内的元素（并发安全）
+// 与 Redis ZRANGE 一致，负数下标表示从末尾倒数（-1 为最后一个元素），越界部分自动截断
+// 定位起点 O(log n)，之后沿第 0 层顺序收集
+func (sl *SkipList[T]) RangeByRank(start, stop int) []T {
+	if sl.mu != nil {
+		sl.mu.RLock()
+		defer sl.mu.RUnlock()
+	}
+
+	start, stop, ok := normalizeRankRange(start, stop, sl.length)
+	if !ok {
+		return []T{}
+	}
+
+	result := make([]T, 0, stop-start+1)
+	for current := sl.nodeByRank(start); current != nil && start <= stop; start++ {
+		result = append(result, current.value)
+		current = current.level[0]
+	}
+
+	return result
+}
+
+// DeleteRangeByRank 删除排名在闭区间 [start, stop] 内的元素（并发安全）
+// 下标规则同 RangeByRank；返回删除的元素个数
+func (sl *SkipList[T]) DeleteRangeByRank(start, stop int) int {
+	if sl.mu != nil {
+		sl.mu.Lock()
+		defer sl.mu.Unlock()
+	}
+
+	return sl.deleteRangeByRank(start, stop, nil)
+}
+
+// deleteRangeByRank 是 DeleteRangeByRank 的无锁实现
+// onDelete 非 nil 时，对每个被删除的元素按升序回调
+func (sl *SkipList[T]) deleteRangeByRank(start, stop int, onDelete func(T)) int {
+	start, stop, ok := normalizeRankRange(start, stop, sl.length)
+	if !ok {
+		return 0
+	}
+
+	// 找到排名 start 的节点在每一层的前驱
+	predecessors := make([]*Node[T], sl.maxLevel)
+	traversed := 0
+	current := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.level[i] != nil && traversed+current.span[i] <= start {
+			traversed += current.span[i]
+			current = current.level[i]
+		}
+		predecessors[i] = current
+	}
+
+	// 逐个摘除；前驱保持不变，被删节点的后继依次补位
+	removed := 0
+	current = current.level[0]
+	for current != nil && removed < stop-start+1 {
+		next := current.level[0]
+		sl.deleteNode(current, predecessors)
+		if onDelete != nil {
+			onDelete(current.value)
+		}
+		removed++
+		current = next
+	}
+
+	return removed
+}
+
 // ═══════════════════════════════════════════════════════
 // 辅助操作
 // ═══════════════════════════════════════════════════════
@@ -345,9 +545,7 @@ func (sl *SkipList[T]) Clear() {
 		defer sl.mu.Unlock()
 	}
 
-	sl.head = &Node[T]{
-		level: make([]*Node[T], sl.maxLevel),
-	}
+	sl.head = sl.createNode(*new(T), sl.maxLevel)
 	sl.level = 1
 	sl.length = 0
 }
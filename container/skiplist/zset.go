@@ -0,0 +1,259 @@
+package skiplist
+
+import (
+	"cmp"
+	"sync"
+)
+
+// ZEntry 有序集合中的一个条目
+type ZEntry[M cmp.Ordered] struct {
+	Member M       // 成员
+	Score  float64 // 分值
+}
+
+// ZSet Redis 风格的有序集合
+// 由 member→score 的哈希表与按 (score, member) 排序的带跨度跳表组成：
+// 哈希表负责 O(1) 查分，跳表负责按分值/排名的 O(log n) 查询
+type ZSet[M cmp.Ordered] struct {
+	dict map[M]float64        // 成员 → 分值
+	sl   *SkipList[ZEntry[M]] // 按 (score, member) 升序排列
+	mu   *sync.RWMutex        // 读写锁，保证并发安全
+}
+
+// ═══════════════════════════════════════════════════════
+// 构造函数
+// ═══════════════════════════════════════════════════════
+
+// NewZSet 创建新的有序集合
+// 分值相同的成员按 member 升序排列（与 Redis 一致）
+func NewZSet[M cmp.Ordered](withRWLock bool) *ZSet[M] {
+	var rw_lock *sync.RWMutex
+	if withRWLock {
+		rw_lock = new(sync.RWMutex)
+	}
+
+	return &ZSet[M]{
+		dict: make(map[M]float64),
+		sl:   New(zentryBetter[M], false), // 并发由 ZSet 自身的锁保证
+		mu:   rw_lock,
+	}
+}
+
+// zentryBetter 先按分值升序，分值相同再按成员升序
+func zentryBetter[M cmp.Ordered](a, b ZEntry[M]) bool {
+	if a.Score != b.Score {
+		return a.Score < b.Score
+	}
+	return a.Member < b.Member
+}
+
+func (z *ZSet[M]) lock() {
+	if z.mu != nil {
+		z.mu.Lock()
+	}
+}
+
+func (z *ZSet[M]) unlock() {
+	if z.mu != nil {
+		z.mu.Unlock()
+	}
+}
+
+func (z *ZSet[M]) rlock() {
+	if z.mu != nil {
+		z.mu.RLock()
+	}
+}
+
+func (z *ZSet[M]) runlock() {
+	if z.mu != nil {
+		z.mu.RUnlock()
+	}
+}
+
+// ═══════════════════════════════════════════════════════
+// 写操作
+// ═══════════════════════════════════════════════════════
+
+// ZAdd 添加成员或更新已有成员的分值
+// 返回 true 表示新增成员；false 表示成员已存在（分值被更新或保持不变）
+func (z *ZSet[M]) ZAdd(member M, score float64) bool {
+	z.lock()
+	defer z.unlock()
+
+	return z.zadd(member, score)
+}
+
+// zadd 是 ZAdd 的无锁实现
+func (z *ZSet[M]) zadd(member M, score float64) bool {
+	old, exists := z.dict[member]
+	if exists {
+		if old == score {
+			return false
+		}
+		// 分值变化：先按旧键摘除，再按新键插入
+		z.sl.Delete(ZEntry[M]{Member: member, Score: old})
+	}
+
+	z.sl.Insert(ZEntry[M]{Member: member, Score: score})
+	z.dict[member] = score
+	return !exists
+}
+
+// ZIncrBy 给成员的分值加上 delta，成员不存在时视为从 0 开始
+// 返回更新后的分值
+func (z *ZSet[M]) ZIncrBy(member M, delta float64) float64 {
+	z.lock()
+	defer z.unlock()
+
+	score := z.dict[member] + delta
+	z.zadd(member, score)
+	return score
+}
+
+// ZRem 删除若干成员，返回实际删除的个数
+func (z *ZSet[M]) ZRem(members ...M) int {
+	z.lock()
+	defer z.unlock()
+
+	removed := 0
+	for _, member := range members {
+		score, ok := z.dict[member]
+		if !ok {
+			continue
+		}
+		z.sl.Delete(ZEntry[M]{Member: member, Score: score})
+		delete(z.dict, member)
+		removed++
+	}
+	return removed
+}
+
+// ZRemRangeByRank 删除排名在闭区间 [start, stop] 内的成员，返回删除个数
+// 下标规则同 ZRange
+func (z *ZSet[M]) ZRemRangeByRank(start, stop int) int {
+	z.lock()
+	defer z.unlock()
+
+	return z.sl.deleteRangeByRank(start, stop, func(e ZEntry[M]) {
+		delete(z.dict, e.Member)
+	})
+}
+
+// ═══════════════════════════════════════════════════════
+// 查询操作
+// ═══════════════════════════════════════════════════════
+
+// ZCard 返回成员个数
+func (z *ZSet[M]) ZCard() int {
+	z.rlock()
+	defer z.runlock()
+
+	return len(z.dict)
+}
+
+// ZScore 返回成员的分值；成员不存在返回 (0, false)
+func (z *ZSet[M]) ZScore(member M) (float64, bool) {
+	z.rlock()
+	defer z.runlock()
+
+	score, ok := z.dict[member]
+	return score, ok
+}
+
+// ZRank 返回成员按分值升序的 0-based 排名；成员不存在返回 (-1, false)
+func (z *ZSet[M]) ZRank(member M) (int, bool) {
+	z.rlock()
+	defer z.runlock()
+
+	score, ok := z.dict[member]
+	if !ok {
+		return -1, false
+	}
+	return z.sl.Rank(ZEntry[M]{Member: member, Score: score})
+}
+
+// ZRevRank 返回成员按分值降序的 0-based 排名；成员不存在返回 (-1, false)
+func (z *ZSet[M]) ZRevRank(member M) (int, bool) {
+	z.rlock()
+	defer z.runlock()
+
+	score, ok := z.dict[member]
+	if !ok {
+		return -1, false
+	}
+	rank, ok := z.sl.Rank(ZEntry[M]{Member: member, Score: score})
+	if !ok {
+		return -1, false
+	}
+	return z.sl.length - 1 - rank, true
+}
+
+// ZRange 返回按分值升序、排名在闭区间 [start, stop] 内的条目
+// 与 Redis 一致，负数下标表示从末尾倒数（-1 为最后一个），越界部分自动截断
+func (z *ZSet[M]) ZRange(start, stop int) []ZEntry[M] {
+	z.rlock()
+	defer z.runlock()
+
+	return z.sl.RangeByRank(start, stop)
+}
+
+// ZRevRange 返回按分值降序、排名在闭区间 [start, stop] 内的条目
+// 下标规则同 ZRange，但排名从最大分值开始计算
+func (z *ZSet[M]) ZRevRange(start, stop int) []ZEntry[M] {
+	z.rlock()
+	defer z.runlock()
+
+	length := z.sl.length
+	start, stop, ok := normalizeRankRange(start, stop, length)
+	if !ok {
+		return []ZEntry[M]{}
+	}
+
+	// 降序排名 [start, stop] 对应升序排名 [length-1-stop, length-1-start]
+	result := z.sl.RangeByRank(length-1-stop, length-1-start)
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// ZRangeByScore 返回分值在闭区间 [min, max] 内的条目，按分值升序
+// offset / count 对应 Redis 的 LIMIT 子句：跳过前 offset 个结果，最多返回 count 个；
+// count < 0 表示不限制数量
+// 定位起点为 O(log n)（包括跳过 offset），之后沿第 0 层顺序收集
+func (z *ZSet[M]) ZRangeByScore(min, max float64, offset, count int) []ZEntry[M] {
+	z.rlock()
+	defer z.runlock()
+
+	result := make([]ZEntry[M], 0)
+	if offset < 0 || count == 0 || min > max {
+		return result
+	}
+
+	first := z.sl.lowerBound(func(e ZEntry[M]) bool { return e.Score < min })
+	for current := z.sl.nodeByRank(first + offset); current != nil; current = current.level[0] {
+		if current.value.Score > max {
+			break
+		}
+		if count >= 0 && len(result) >= count {
+			break
+		}
+		result = append(result, current.value)
+	}
+
+	return result
+}
+
+// ZCount 返回分值在闭区间 [min, max] 内的成员个数，O(log n)
+func (z *ZSet[M]) ZCount(min, max float64) int {
+	z.rlock()
+	defer z.runlock()
+
+	if min > max {
+		return 0
+	}
+	first := z.sl.lowerBound(func(e ZEntry[M]) bool { return e.Score < min })
+	last := z.sl.lowerBound(func(e ZEntry[M]) bool { return e.Score <= max })
+	return last - first
+}
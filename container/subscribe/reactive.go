@@ -0,0 +1,268 @@
+package subscribe
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/leoheung/go-patterns/container/tree/heap"
+)
+
+// ═══════════════════════════════════════════════════════
+// 依赖图与无毛刺(glitch-free)传播
+// ═══════════════════════════════════════════════════════
+//
+// 每个 ValueProvider / Derived 是依赖图中的一个顶点,height = 1 + 各来源 height 的最大值(源头为 0)。
+// 源头变化时,在 graphMu 内按 height 从小到大重算受影响的派生值:
+// 一个顶点被重算时,它的全部来源都已经是本轮的最终值,且每个顶点每轮最多重算一次。
+// 因此菱形依赖(a → b、a → c、(b, c) → d)中 d 不会看到「b 已更新、c 仍是旧值」的中间状态。
+// 全部重算完成后才依次通知各顶点的订阅者。
+
+// graphMu 串行化依赖图的变更与传播;加锁顺序:graphMu → 各 hub.mu
+var graphMu sync.Mutex
+
+type vertex struct {
+	height     int
+	dependents []*vertex
+	ndeps      atomic.Int32  // len(dependents),供 Set 的快速路径无锁判断
+	mu         *sync.RWMutex // 所属 hub 的锁,注册依赖时与 Set 的快速路径互斥
+	recompute  func() bool   // 由来源重算自身,返回值是否变化;源头为 nil
+	publish    func()        // 通知订阅者
+	closed     bool
+}
+
+func (vx *vertex) addDependent(d *vertex) {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	vx.dependents = append(vx.dependents, d)
+	vx.ndeps.Store(int32(len(vx.dependents)))
+}
+
+func (vx *vertex) removeDependent(d *vertex) {
+	vx.mu.Lock()
+	defer vx.mu.Unlock()
+	for i, dep := range vx.dependents {
+		if dep == d {
+			vx.dependents = append(vx.dependents[:i], vx.dependents[i+1:]...)
+			break
+		}
+	}
+	vx.ndeps.Store(int32(len(vx.dependents)))
+}
+
+// propagate 在 from 的值已更新后重算全部下游并通知订阅者;调用方持有 graphMu
+func propagate(from *vertex) {
+	changed := []*vertex{from}
+	queued := make(map[*vertex]bool)
+	pq := heap.NewBinaryHeap(func(a, b *vertex) bool { return a.height < b.height })
+	enqueue := func(vx *vertex) {
+		for _, d := range vx.dependents {
+			if !queued[d] && !d.closed {
+				queued[d] = true
+				pq.Push(d)
+			}
+		}
+	}
+
+	enqueue(from)
+	for pq.Len() > 0 {
+		vx, _ := pq.Pop()
+		if vx.recompute() {
+			changed = append(changed, vx)
+			enqueue(vx)
+		}
+	}
+	for _, vx := range changed {
+		vx.publish()
+	}
+}
+
+// ═══════════════════════════════════════════════════════
+// Derived:只读的派生值
+// ═══════════════════════════════════════════════════════
+
+// Derived 是由其他 Observable 派生出的只读值,来源变化时自动重算。
+// 不再使用时调用 Close:从来源上注销并关闭全部订阅;之后值停留在最后一次结果,依赖它的派生值也不再更新。
+type Derived[T any] struct {
+	hub     hub[T]
+	vx      vertex
+	sources []*vertex
+	stop    func() // Debounce 用于停止定时器
+}
+
+// newDerived 在 graphMu 内注册到各来源并用 initial 计算初值
+func newDerived[T any](sources []*vertex, initial func() T, recompute func(d *Derived[T]) bool) *Derived[T] {
+	graphMu.Lock()
+	defer graphMu.Unlock()
+
+	d := &Derived[T]{sources: sources}
+	height := 0
+	for _, src := range sources {
+		height = max(height, src.height+1)
+		src.addDependent(&d.vx)
+	}
+	// 先注册再读初值:此后来源的 Set 都会走依赖图,在本函数返回后传播过来
+	d.hub = newHub(initial(), DefaultHistorySize)
+	d.vx.height = height
+	d.vx.mu = &d.hub.mu
+	d.vx.recompute = func() bool { return recompute(d) }
+	d.vx.publish = d.hub.publish
+	return d
+}
+
+func (d *Derived[T]) Get() T {
+	return d.hub.get()
+}
+
+// GetWithVersion 同时返回当前值和它的版本号;每次重算产生新值时加一。
+func (d *Derived[T]) GetWithVersion() (T, uint64) {
+	return d.hub.getWithVersion()
+}
+
+// Subscribe 以 DropOldest 策略订阅,最多积压 buffer 个值;返回值通道和取消订阅函数。
+func (d *Derived[T]) Subscribe(buffer int) (<-chan T, func(), error) {
+	s, _ := d.SubscribeWith(SubscribeOptions{Policy: DropOldest, Buffer: buffer})
+	return s.C, s.Unsubscribe, nil
+}
+
+// SubscribeWith 按 opts 订阅;opts.StartWithCurrent / opts.Replay 可先收到当前值或最近的历史版本。
+func (d *Derived[T]) SubscribeWith(opts SubscribeOptions) (*Subscription[T], error) {
+	return d.hub.subscribe(opts), nil
+}
+
+// Watch 以 LatestOnly 策略订阅,ctx 结束时自动取消订阅并关闭返回的通道。
+func (d *Derived[T]) Watch(ctx context.Context) (<-chan T, error) {
+	s, _ := d.SubscribeWith(SubscribeOptions{Policy: LatestOnly})
+	return watch(ctx, s), nil
+}
+
+// Close 从来源上注销并关闭全部订阅;可重复调用。
+func (d *Derived[T]) Close() {
+	graphMu.Lock()
+	if d.vx.closed {
+		graphMu.Unlock()
+		return
+	}
+	d.vx.closed = true
+	for _, src := range d.sources {
+		src.removeDependent(&d.vx)
+	}
+	if d.stop != nil {
+		d.stop()
+	}
+	graphMu.Unlock()
+
+	d.hub.unsubscribeAll()
+}
+
+func (d *Derived[T]) vertex() *vertex {
+	return &d.vx
+}
+
+// ═══════════════════════════════════════════════════════
+// 组合子
+// ═══════════════════════════════════════════════════════
+
+// Map 派生出 fn(src)。
+//
+// fn 在持有 graphMu 时执行:fn 内对另一个有派生值依赖的 ValueProvider 调用 Set 会死锁。
+func Map[S, R any](src Observable[S], fn func(S) R) *Derived[R] {
+	return newDerived(
+		[]*vertex{src.vertex()},
+		func() R { return fn(src.Get()) },
+		func(d *Derived[R]) bool {
+			d.hub.store(fn(src.Get()))
+			return true
+		},
+	)
+}
+
+// Combine 派生出 fn(a, b),a、b 任一变化时重算;a、b 同源时每轮只重算一次。
+//
+// 与 Map 相同,fn 在持有 graphMu 时执行,不能在其中对有派生值依赖的 ValueProvider 调用 Set。
+func Combine[A, B, R any](a Observable[A], b Observable[B], fn func(A, B) R) *Derived[R] {
+	return newDerived(
+		[]*vertex{a.vertex(), b.vertex()},
+		func() R { return fn(a.Get(), b.Get()) },
+		func(d *Derived[R]) bool {
+			d.hub.store(fn(a.Get(), b.Get()))
+			return true
+		},
+	)
+}
+
+// Filter 只接受满足 pred 的值,其余变化被忽略。
+// 初值为 src 的当前值(若满足 pred),否则为零值。
+func Filter[T any](src Observable[T], pred func(T) bool) *Derived[T] {
+	return newDerived(
+		[]*vertex{src.vertex()},
+		func() T {
+			if v := src.Get(); pred(v) {
+				return v
+			}
+			var zero T
+			return zero
+		},
+		func(d *Derived[T]) bool {
+			v := src.Get()
+			if !pred(v) {
+				return false
+			}
+			d.hub.store(v)
+			return true
+		},
+	)
+}
+
+// DistinctUntilChanged 只在新值与当前值按 equal 不相等时更新,下游与订阅者不会收到重复值。
+func DistinctUntilChanged[T any](src Observable[T], equal func(a, b T) bool) *Derived[T] {
+	return newDerived(
+		[]*vertex{src.vertex()},
+		src.Get,
+		func(d *Derived[T]) bool {
+			v := src.Get()
+			if equal(d.hub.get(), v) {
+				return false
+			}
+			d.hub.store(v)
+			return true
+		},
+	)
+}
+
+// Debounce 在 src 静默 wait 之后才采用它的最新值;期间的连续变化只产生一次更新。
+// 更新发生在定时器 goroutine 中,并从本顶点开始重新传播给下游。
+func Debounce[T any](src Observable[T], wait time.Duration) *Derived[T] {
+	var timer *time.Timer
+	var d *Derived[T]
+	fire := func() {
+		graphMu.Lock()
+		defer graphMu.Unlock()
+		if d.vx.closed {
+			return
+		}
+		d.hub.store(src.Get())
+		propagate(&d.vx)
+	}
+
+	d = newDerived(
+		[]*vertex{src.vertex()},
+		src.Get,
+		func(d *Derived[T]) bool {
+			// 在 graphMu 内重置定时器,本轮不产生变化
+			if timer == nil {
+				timer = time.AfterFunc(wait, fire)
+			} else {
+				timer.Reset(wait)
+			}
+			return false
+		},
+	)
+	d.stop = func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+	return d
+}
@@ -0,0 +1,149 @@
+package subscribe
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestDiamondGlitchFree 菱形依赖 a → b、a → c、(b, c) → d:d 每轮只重算一次,且从不看到 b、c 来自不同轮次
+func TestDiamondGlitchFree(t *testing.T) {
+	a := NewValueProvider(1, 0)
+	b := Map(a, func(x int) int { return x * 10 })
+	c := Map(a, func(x int) int { return x * 100 })
+
+	var glitches, calls int // fn 在 graphMu 内执行,无需额外同步
+	d := Combine(b, c, func(b, c int) int {
+		calls++
+		if c != b*10 {
+			glitches++
+		}
+		return b + c
+	})
+	defer d.Close()
+
+	const writers, perW = 4, 500
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := range perW {
+				a.Set(w*perW + i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	graphMu.Lock()
+	defer graphMu.Unlock()
+	if glitches != 0 {
+		t.Fatalf("Combine observed %d inconsistent (b, c) pairs", glitches)
+	}
+	if want := 1 + writers*perW; calls != want {
+		t.Fatalf("Combine fn ran %d times, want %d (once per Set plus the initial value)", calls, want)
+	}
+	if got, want := d.Get(), a.Get()*110; got != want {
+		t.Fatalf("d = %d, want %d", got, want)
+	}
+}
+
+// TestDerivedSubscribers 派生值的订阅者在整轮重算完成后收到新值
+func TestDerivedSubscribers(t *testing.T) {
+	a := NewValueProvider(1, 0)
+	sum := Combine(a, Map(a, func(x int) int { return -x }), func(x, y int) int { return x + y })
+	s, _ := sum.SubscribeWith(SubscribeOptions{Policy: DropOldest, Buffer: 16})
+	defer sum.Close()
+
+	for i := 2; i <= 5; i++ {
+		a.Set(i)
+	}
+	for range 4 {
+		if v, _ := recv(t, s.C); v != 0 {
+			t.Fatalf("subscriber saw %d, want 0", v)
+		}
+	}
+}
+
+// TestFilterAndDistinct Filter 忽略不满足条件的值,DistinctUntilChanged 不产生重复值
+func TestFilterAndDistinct(t *testing.T) {
+	a := NewValueProvider(1, 0)
+	even := Filter(a, func(x int) bool { return x%2 == 0 })
+	half := DistinctUntilChanged(Map(a, func(x int) int { return x / 2 }), func(x, y int) bool { return x == y })
+	defer even.Close()
+	defer half.Close()
+
+	if even.Get() != 0 {
+		t.Fatalf("Filter initial = %d, want zero value", even.Get())
+	}
+	_, v0 := half.GetWithVersion()
+	for _, x := range []int{2, 3, 5, 4} {
+		a.Set(x)
+	}
+	if even.Get() != 4 {
+		t.Fatalf("Filter = %d, want 4", even.Get())
+	}
+	// 1/2=0 → 2/2=1 → 3/2=1 → 5/2=2 → 4/2=2:只变化两次
+	if v, ver := half.GetWithVersion(); v != 2 || ver != v0+2 {
+		t.Fatalf("DistinctUntilChanged = %d at version %d, want 2 at version %d", v, ver, v0+2)
+	}
+}
+
+// TestDerivedClose Close 从来源注销、关闭订阅,之后来源变化不再重算
+func TestDerivedClose(t *testing.T) {
+	a := NewValueProvider(1, 0)
+	calls := 0
+	m := Map(a, func(x int) int {
+		calls++
+		return x
+	})
+	sq := Map(m, func(x int) int { return x * x })
+	ch, _, _ := m.Subscribe(1)
+
+	m.Close()
+	m.Close()
+	if n := a.vx.ndeps.Load(); n != 0 {
+		t.Fatalf("source still has %d dependents after Close", n)
+	}
+	if _, ok := recv(t, ch); ok {
+		t.Fatal("subscription channel not closed after Close")
+	}
+
+	a.Set(7)
+	if calls != 1 || m.Get() != 1 || sq.Get() != 1 {
+		t.Fatalf("after Close: calls = %d, m = %d, sq = %d", calls, m.Get(), sq.Get())
+	}
+	sq.Close()
+	if n := m.vx.ndeps.Load(); n != 0 {
+		t.Fatalf("closed Derived still has %d dependents", n)
+	}
+}
+
+// TestDebounce 连续变化只在静默后产生一次更新;Close 停止尚未触发的定时器
+func TestDebounce(t *testing.T) {
+	const wait = 100 * time.Millisecond
+	a := NewValueProvider(0, 0)
+	db := Debounce(a, wait)
+	defer db.Close()
+
+	_, v0 := db.GetWithVersion()
+	for i := 1; i <= 5; i++ {
+		a.Set(i)
+	}
+	eventually(t, "debounced value", func() bool { return db.Get() == 5 })
+	time.Sleep(3 * wait)
+	if _, ver := db.GetWithVersion(); ver != v0+1 {
+		t.Fatalf("Debounce updated %d times, want 1", ver-v0)
+	}
+
+	closed := Debounce(a, wait)
+	a.Set(6)
+	closed.Close()
+	time.Sleep(3 * wait)
+	if v := closed.Get(); v != 5 {
+		t.Fatalf("Debounce fired after Close: value = %d, want 5", v)
+	}
+	if n := a.vx.ndeps.Load(); n != 1 {
+		t.Fatalf("source has %d dependents, want 1 after closing the second Debounce", n)
+	}
+}
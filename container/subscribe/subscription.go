@@ -0,0 +1,193 @@
+package subscribe
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/leoheung/go-patterns/utils"
+)
+
+// Policy 决定订阅者跟不上 Set 的速度时如何处理积压的值。
+type Policy int
+
+const (
+	// LatestOnly 只保留最新值:尚未送达的旧值被新值覆盖(计入 Dropped),适合配置 / 状态类订阅
+	LatestOnly Policy = iota
+	// DropOldest 最多积压 Buffer 个值,溢出时丢弃最旧的
+	DropOldest
+	// BlockWithTimeout 最多积压 Buffer 个值(溢出时丢弃最旧的),每个值最多等待订阅者 Timeout,超时未被接收则丢弃。
+	// Timeout 从 Set 写入该值时起算,而不是从开始投递时起算:积压中排队的时间同样计入
+	BlockWithTimeout
+)
+
+// SubscribeOptions 订阅选项,零值表示 LatestOnly。
+type SubscribeOptions struct {
+	Policy  Policy
+	Buffer  int           // DropOldest / BlockWithTimeout 的积压上限,<= 0 时取 1
+	Timeout time.Duration // BlockWithTimeout 的单值等待上限(自 Set 起算),<= 0 时取 1s
+
+	// StartWithCurrent 订阅后先收到当前值
+	StartWithCurrent bool
+	// Replay 订阅后先按版本顺序收到最近 Replay 个版本(含当前值),最多为 provider 保留的历史数。
+	// 回放的值同样受 Policy 约束:LatestOnly 只会收到其中最新的一个,DropOldest / BlockWithTimeout 需要 Buffer >= Replay 才能全部收到
+	Replay int
+}
+
+const defaultDeliveryTimeout = time.Second
+
+// Subscription 是一次订阅。值从 C 读出;Unsubscribe 之后 C 会被关闭。
+//
+// 每个订阅有独立的信箱和投递 goroutine:Set 只把值放进信箱,从不阻塞;
+// 慢订阅者只会在自己的信箱里积压或丢值,不影响 Set 和其他订阅者。
+type Subscription[T any] struct {
+	C <-chan T
+
+	out     chan T
+	opts    SubscribeOptions
+	mu      sync.Mutex
+	queue   []pending[T]
+	signal  chan struct{} // 信箱由空变非空 / 被覆盖时通知投递 goroutine
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+	remove  func() // 从 provider 注销
+}
+
+type pending[T any] struct {
+	val T
+	at  time.Time
+}
+
+func newSubscription[T any](opts SubscribeOptions, remove func()) *Subscription[T] {
+	if opts.Buffer <= 0 {
+		opts.Buffer = 1
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultDeliveryTimeout
+	}
+	out := make(chan T)
+	s := &Subscription[T]{
+		C:      out,
+		out:    out,
+		opts:   opts,
+		signal: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		remove: remove,
+	}
+	go s.pump()
+	return s
+}
+
+// Dropped 返回因策略被丢弃(覆盖、溢出或超时)的值的个数。
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe 取消订阅并关闭 C;可重复调用。
+func (s *Subscription[T]) Unsubscribe() {
+	s.once.Do(func() {
+		if s.remove != nil {
+			s.remove()
+		}
+		close(s.done)
+	})
+}
+
+// offer 把 val 放进信箱,不阻塞
+func (s *Subscription[T]) offer(val T) {
+	s.mu.Lock()
+	switch s.opts.Policy {
+	case LatestOnly:
+		if len(s.queue) > 0 {
+			s.queue = s.queue[:0]
+			s.dropped.Add(1)
+		}
+	case DropOldest, BlockWithTimeout:
+		if len(s.queue) >= s.opts.Buffer {
+			s.queue = s.queue[1:]
+			s.dropped.Add(1)
+		}
+	}
+	s.queue = append(s.queue, pending[T]{val: val, at: time.Now()})
+	s.mu.Unlock()
+	utils.TryEnqueue(s.signal, struct{}{})
+}
+
+func (s *Subscription[T]) take() (pending[T], bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return pending[T]{}, false
+	}
+	p := s.queue[0]
+	var zero pending[T]
+	s.queue[0] = zero
+	s.queue = s.queue[1:]
+	return p, true
+}
+
+// pump 是唯一向 out 发送的 goroutine,退出时关闭 out
+func (s *Subscription[T]) pump() {
+	defer close(s.out)
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.signal:
+		}
+
+		for {
+			p, ok := s.take()
+			if !ok {
+				break
+			}
+			if !s.deliver(p) {
+				return
+			}
+		}
+	}
+}
+
+// deliver 按策略把 p 送给订阅者;订阅被取消时返回 false
+func (s *Subscription[T]) deliver(p pending[T]) bool {
+	switch s.opts.Policy {
+	case LatestOnly:
+		// 投递途中来了新值:放弃手上的旧值,改送最新值
+		for {
+			select {
+			case s.out <- p.val:
+				return true
+			case <-s.done:
+				return false
+			case <-s.signal:
+				next, ok := s.take()
+				if ok {
+					s.dropped.Add(1)
+					p = next
+				}
+			}
+		}
+
+	case BlockWithTimeout:
+		timer := time.NewTimer(time.Until(p.at.Add(s.opts.Timeout)))
+		defer timer.Stop()
+		select {
+		case s.out <- p.val:
+		case <-s.done:
+			return false
+		case <-timer.C:
+			s.dropped.Add(1)
+		}
+		return true
+
+	default:
+		select {
+		case s.out <- p.val:
+			return true
+		case <-s.done:
+			return false
+		}
+	}
+}
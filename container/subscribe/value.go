@@ -1,78 +1,135 @@
 package subscribe
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
 	lctx "github.com/leoheung/go-patterns/container/context"
-	"github.com/leoheung/go-patterns/container/safeslice"
-	"github.com/leoheung/go-patterns/utils"
 )
 
+// Observable 是可读、可订阅的值:ValueProvider 与派生值 Derived 都实现了它,可作为组合子的输入。
+type Observable[T any] interface {
+	Get() T
+	SubscribeWith(opts SubscribeOptions) (*Subscription[T], error)
+	Watch(ctx context.Context) (<-chan T, error)
+
+	vertex() *vertex
+}
+
+var (
+	_ Observable[int] = (*ValueProvider[int])(nil)
+	_ Observable[int] = (*Derived[int])(nil)
+)
+
+// ValueProvider 持有一个可被订阅的值。Set 在锁内更新值并把新值放进每个订阅者的信箱,
+// 从不阻塞;每个订阅者按自己的 SubscribeOptions 处理积压,见 Subscription。
+// 有派生值依赖它时,Set 还会同步地按依赖图重算全部下游,见 Map / Combine。
 type ValueProvider[T any] struct {
-	value       T
-	subscribers *safeslice.SafeSlice[chan T]
-	valueBuffer chan T
-	mu          sync.RWMutex
-	ctx         *lctx.RenewableContext[any]
+	hub hub[T]
+	vx  vertex
+	ctx *lctx.RenewableContext[any]
 }
 
+// NewValueProvider 创建 ValueProvider,保留最近 DefaultHistorySize 个版本供 Replay 使用。
+//
+// Deprecated: buffer_size 已不再使用,Set 不再经过共享缓冲而是直接投递到各订阅者的信箱;
+// 保留该参数只为兼容已有调用方,积压上限请通过 Subscribe / SubscribeWith 按订阅者设置。
 func NewValueProvider[T any](val T, buffer_size int) *ValueProvider[T] {
-	if buffer_size < 0 {
-		buffer_size = 0
-	}
+	return NewValueProviderWithHistory(val, DefaultHistorySize)
+}
 
-	ret := &ValueProvider[T]{
-		value:       val,
-		subscribers: safeslice.NewSafeSlice[chan T](0, 0),
-		valueBuffer: make(chan T, buffer_size),
-		mu:          sync.RWMutex{},
-		ctx:         lctx.NewRenewableContext[any](nil, nil),
+// NewValueProviderWithHistory 创建 ValueProvider,保留最近 history 个版本(含当前值)供 Replay 使用;
+// history <= 0 时取 1,即只保留当前值。
+func NewValueProviderWithHistory[T any](val T, history int) *ValueProvider[T] {
+	v := &ValueProvider[T]{
+		hub: newHub(val, history),
+		ctx: lctx.NewRenewableContext[any](nil, nil),
 	}
-
-	go ret.notify_worker()
-
-	return ret
+	v.vx.mu = &v.hub.mu
+	v.vx.publish = v.hub.publish
+	return v
 }
 
 func (v *ValueProvider[T]) Get() T {
-	v.mu.RLock()
-	defer v.mu.RUnlock()
-	return v.value
+	return v.hub.get()
 }
 
+// GetWithVersion 同时返回当前值和它的版本号;初始值的版本为 0,每次 Set 加一。
+func (v *ValueProvider[T]) GetWithVersion() (T, uint64) {
+	return v.hub.getWithVersion()
+}
+
+// Set 更新值,版本号加一。
 func (v *ValueProvider[T]) Set(val T) error {
-	v.mu.Lock()
-	defer v.mu.Unlock()
+	_, err := v.set(val, nil)
+	return err
+}
+
+// CompareAndSet 仅当当前版本等于 expectedVersion 时更新值,用于乐观并发控制:
+// 先 GetWithVersion 读出值和版本,计算新值后 CompareAndSet,返回 false 表示期间已被他人修改,应重读重试。
+func (v *ValueProvider[T]) CompareAndSet(expectedVersion uint64, val T) (bool, error) {
+	return v.set(val, &expectedVersion)
+}
 
+// set 在 expected 非 nil 时只在版本匹配时更新
+func (v *ValueProvider[T]) set(val T, expected *uint64) (bool, error) {
+	v.hub.mu.Lock()
 	if !v.ctx.IsAlive() {
-		return fmt.Errorf("ValueProvider is paused")
+		v.hub.mu.Unlock()
+		return false, fmt.Errorf("ValueProvider is paused")
+	}
+	if expected != nil && v.hub.version != *expected {
+		v.hub.mu.Unlock()
+		return false, nil
 	}
+	if v.vx.ndeps.Load() == 0 {
+		// 没有派生值依赖,无需进入依赖图
+		v.hub.store_locked(val)
+		v.hub.offer_locked()
+		v.hub.mu.Unlock()
+		return true, nil
+	}
+	v.hub.mu.Unlock()
 
-	v.value = val
-	v.valueBuffer <- val
-	return nil
+	graphMu.Lock()
+	defer graphMu.Unlock()
+	if !v.ctx.IsAlive() {
+		return false, fmt.Errorf("ValueProvider is paused")
+	}
+	if !v.hub.compareAndStore(expected, val) {
+		return false, nil
+	}
+	propagate(&v.vx)
+	return true, nil
 }
 
+// Subscribe 以 DropOldest 策略订阅,最多积压 buffer 个值;返回值通道和取消订阅函数。
+//
+// 注意:积压满时丢弃的是最旧的值,订阅者总能收到最新值;旧版本在缓冲满时丢弃的是新到的值。
 func (v *ValueProvider[T]) Subscribe(buffer int) (<-chan T, func(), error) {
-	if !v.ctx.IsAlive() {
-		return nil, nil, fmt.Errorf("ValueProvider is paused")
+	s, err := v.SubscribeWith(SubscribeOptions{Policy: DropOldest, Buffer: buffer})
+	if err != nil {
+		return nil, nil, err
 	}
+	return s.C, s.Unsubscribe, nil
+}
 
-	if buffer < 0 {
-		buffer = 0
+// SubscribeWith 按 opts 订阅;opts.StartWithCurrent / opts.Replay 可先收到当前值或最近的历史版本。
+func (v *ValueProvider[T]) SubscribeWith(opts SubscribeOptions) (*Subscription[T], error) {
+	if !v.ctx.IsAlive() {
+		return nil, fmt.Errorf("ValueProvider is paused")
 	}
-	ch := make(chan T, buffer)
-	v.subscribers.Append(ch)
+	return v.hub.subscribe(opts), nil
+}
 
-	unsubscribe := func() {
-		removed_count := v.subscribers.RemoveIf(func(c chan T) bool { return c == ch })
-		if removed_count == 1 {
-			close(ch)
-		}
+// Watch 以 LatestOnly 策略订阅,ctx 结束时自动取消订阅并关闭返回的通道。
+func (v *ValueProvider[T]) Watch(ctx context.Context) (<-chan T, error) {
+	s, err := v.SubscribeWith(SubscribeOptions{Policy: LatestOnly})
+	if err != nil {
+		return nil, err
 	}
-
-	return ch, unsubscribe, nil
+	return watch(ctx, s), nil
 }
 
 func (v *ValueProvider[T]) Pause() {
@@ -83,21 +140,136 @@ func (v *ValueProvider[T]) Resume() error {
 	return v.ctx.Reactivate(nil)
 }
 
-func (v *ValueProvider[T]) notify_worker() {
-	resume_ch := v.ctx.SubscribeReactivation()
+func (v *ValueProvider[T]) vertex() *vertex {
+	return &v.vx
+}
 
-	for {
-		_, paused := utils.TryDequeue(v.ctx.Done())
-		if paused {
-			<-resume_ch
+// watch 在 ctx 结束时取消订阅 s
+func watch[T any](ctx context.Context, s *Subscription[T]) <-chan T {
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Unsubscribe()
+		case <-s.done:
 		}
+	}()
+	return s.C
+}
 
-		new_val := <-v.valueBuffer
+// DefaultHistorySize 是 NewValueProvider 保留的历史版本数
+const DefaultHistorySize = 16
 
-		v.subscribers.Range(func(index int, item chan T) bool {
-			utils.TryEnqueue(item, new_val)
-			return true
-		})
+// hub 是当前值、历史版本加订阅者集合,ValueProvider 与 Derived 共用
+type hub[T any] struct {
+	mu          sync.RWMutex
+	value       T
+	version     uint64
+	history     []versioned[T] // 最近的若干版本,按版本递增,末尾为当前值
+	historySize int
+	subscribers map[*Subscription[T]]struct{}
+}
+
+type versioned[T any] struct {
+	version uint64
+	val     T
+}
+
+func newHub[T any](val T, historySize int) hub[T] {
+	historySize = max(historySize, 1)
+	history := make([]versioned[T], 1, historySize)
+	history[0] = versioned[T]{val: val}
+	return hub[T]{
+		value:       val,
+		history:     history,
+		historySize: historySize,
+		subscribers: make(map[*Subscription[T]]struct{}),
+	}
+}
+
+func (h *hub[T]) get() T {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.value
+}
+
+func (h *hub[T]) getWithVersion() (T, uint64) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.value, h.version
+}
+
+func (h *hub[T]) store(val T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.store_locked(val)
+}
+
+// compareAndStore 在 expected 为 nil 或等于当前版本时写入 val
+func (h *hub[T]) compareAndStore(expected *uint64, val T) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if expected != nil && h.version != *expected {
+		return false
+	}
+	h.store_locked(val)
+	return true
+}
+
+func (h *hub[T]) store_locked(val T) {
+	h.value = val
+	h.version++
+	if len(h.history) == h.historySize {
+		copy(h.history, h.history[1:])
+		h.history = h.history[:len(h.history)-1]
+	}
+	h.history = append(h.history, versioned[T]{version: h.version, val: val})
+}
+
+// publish 把当前值放进每个订阅者的信箱
+func (h *hub[T]) publish() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	h.offer_locked()
+}
+
+func (h *hub[T]) offer_locked() {
+	for s := range h.subscribers {
+		s.offer(h.value)
+	}
+}
+
+func (h *hub[T]) subscribe(opts SubscribeOptions) *Subscription[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var s *Subscription[T]
+	s = newSubscription[T](opts, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers, s)
+	})
+	// 在锁内回放历史,保证与之后的 offer 不重不漏
+	n := min(opts.Replay, len(h.history))
+	if opts.StartWithCurrent {
+		n = max(n, 1)
+	}
+	for _, e := range h.history[len(h.history)-n:] {
+		s.offer(e.val)
+	}
+	h.subscribers[s] = struct{}{}
+	return s
+}
+
+// unsubscribeAll 取消全部订阅,关闭各自的通道
+func (h *hub[T]) unsubscribeAll() {
+	h.mu.RLock()
+	subs := make([]*Subscription[T], 0, len(h.subscribers))
+	for s := range h.subscribers {
+		subs = append(subs, s)
+	}
+	h.mu.RUnlock()
 
+	for _, s := range subs {
+		s.Unsubscribe()
 	}
 }
@@ -0,0 +1,219 @@
+package subscribe
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// eventually 轮询 cond,超时失败
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// recv 在超时内从 ch 读出一个值
+func recv[T any](t *testing.T, ch <-chan T) (T, bool) {
+	t.Helper()
+	select {
+	case v, ok := <-ch:
+		return v, ok
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a value")
+		var zero T
+		return zero, false
+	}
+}
+
+// TestSetNeverBlocks 订阅者从不读取时,Set 在每种策略下都不阻塞
+func TestSetNeverBlocks(t *testing.T) {
+	for _, opts := range []SubscribeOptions{
+		{Policy: LatestOnly},
+		{Policy: DropOldest, Buffer: 4},
+		{Policy: BlockWithTimeout, Buffer: 4, Timeout: time.Hour},
+	} {
+		v := NewValueProvider(0, 0)
+		s, err := v.SubscribeWith(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 同时挂一个从不读取的 Subscribe 订阅者
+		_, unsubscribe, _ := v.Subscribe(1)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 1; i <= 100_000; i++ {
+				v.Set(i)
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("policy %d: Set blocked on a subscriber that never reads", opts.Policy)
+		}
+		if got := v.Get(); got != 100_000 {
+			t.Fatalf("Get = %d, want 100000", got)
+		}
+		s.Unsubscribe()
+		unsubscribe()
+	}
+}
+
+// TestLatestOnlyDropped 慢订阅者只收到最新值,其余计入 Dropped
+func TestLatestOnlyDropped(t *testing.T) {
+	const n = 1000
+	v := NewValueProvider(0, 0)
+	s, _ := v.SubscribeWith(SubscribeOptions{Policy: LatestOnly})
+	defer s.Unsubscribe()
+
+	for i := 1; i <= n; i++ {
+		v.Set(i)
+	}
+	received := 0
+	for {
+		val, _ := recv(t, s.C)
+		received++
+		if val == n {
+			break
+		}
+	}
+	if got := s.Dropped(); received+int(got) != n {
+		t.Fatalf("received %d + Dropped %d != %d", received, got, n)
+	}
+}
+
+// TestDropOldestDropped 溢出时丢弃最旧的值,订阅者按顺序收到最后 Buffer 个值
+func TestDropOldestDropped(t *testing.T) {
+	testBoundedDropped(t, SubscribeOptions{Policy: DropOldest, Buffer: 8})
+}
+
+// TestBlockWithTimeoutBounded 积压受 Buffer 约束,溢出计入 Dropped
+func TestBlockWithTimeoutBounded(t *testing.T) {
+	testBoundedDropped(t, SubscribeOptions{Policy: BlockWithTimeout, Buffer: 8, Timeout: time.Hour})
+}
+
+func testBoundedDropped(t *testing.T, opts SubscribeOptions) {
+	const n = 10_000
+	v := NewValueProvider(0, 0)
+	s, _ := v.SubscribeWith(opts)
+	defer s.Unsubscribe()
+
+	for i := 1; i <= n; i++ {
+		v.Set(i)
+		s.mu.Lock()
+		queued := len(s.queue)
+		s.mu.Unlock()
+		if queued > opts.Buffer {
+			t.Fatalf("queue holds %d values, Buffer is %d", queued, opts.Buffer)
+		}
+	}
+
+	// 投递 goroutine 可能在积压前就拿走一个值,也可能直到最后才开始投递;
+	// 无论哪种,收到的都是按顺序的最后 Buffer 个值(前面至多再多一个),其余都计入 Dropped
+	var got []int
+	for len(got) == 0 || got[len(got)-1] != n {
+		val, _ := recv(t, s.C)
+		got = append(got, val)
+	}
+	if len(got) != opts.Buffer && len(got) != opts.Buffer+1 {
+		t.Fatalf("received %d values, want %d or %d", len(got), opts.Buffer, opts.Buffer+1)
+	}
+	for i, val := range got[len(got)-opts.Buffer:] {
+		if want := n - opts.Buffer + 1 + i; val != want {
+			t.Fatalf("received %v, want tail ending with %d..%d", got, n-opts.Buffer+1, n)
+		}
+	}
+	if dropped := s.Dropped(); int(dropped)+len(got) != n {
+		t.Fatalf("Dropped = %d with %d received, want a total of %d", dropped, len(got), n)
+	}
+}
+
+// TestBlockWithTimeoutFromSet 超时从 Set 起算:排队期间已超时的值在轮到投递时直接丢弃
+func TestBlockWithTimeoutFromSet(t *testing.T) {
+	v := NewValueProvider(0, 0)
+	s, _ := v.SubscribeWith(SubscribeOptions{Policy: BlockWithTimeout, Buffer: 8, Timeout: 200 * time.Millisecond})
+	defer s.Unsubscribe()
+
+	for i := 1; i <= 3; i++ {
+		v.Set(i)
+	}
+	eventually(t, "queued values to time out", func() bool { return s.Dropped() == 3 })
+
+	v.Set(4)
+	if val, _ := recv(t, s.C); val != 4 {
+		t.Fatalf("received %d, want 4", val)
+	}
+}
+
+// TestSubscribeReplay StartWithCurrent 与 Replay 先收到当前值 / 历史版本
+func TestSubscribeReplay(t *testing.T) {
+	v := NewValueProviderWithHistory(0, 4)
+	for i := 1; i <= 10; i++ {
+		v.Set(i)
+	}
+	s, _ := v.SubscribeWith(SubscribeOptions{Policy: DropOldest, Buffer: 8, Replay: 8})
+	defer s.Unsubscribe()
+	for want := 7; want <= 10; want++ {
+		if val, _ := recv(t, s.C); val != want {
+			t.Fatalf("replayed %d, want %d", val, want)
+		}
+	}
+
+	cur, _ := v.SubscribeWith(SubscribeOptions{StartWithCurrent: true})
+	defer cur.Unsubscribe()
+	if val, _ := recv(t, cur.C); val != 10 {
+		t.Fatalf("StartWithCurrent received %d, want 10", val)
+	}
+}
+
+// TestWatchClosesOnCancel ctx 取消后 Watch 的通道被关闭
+func TestWatchClosesOnCancel(t *testing.T) {
+	v := NewValueProvider(0, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := v.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Set(1)
+	if val, _ := recv(t, ch); val != 1 {
+		t.Fatalf("received %d, want 1", val)
+	}
+
+	cancel()
+	for {
+		if _, ok := recv(t, ch); !ok {
+			break
+		}
+	}
+	v.hub.mu.RLock()
+	n := len(v.hub.subscribers)
+	v.hub.mu.RUnlock()
+	if n != 0 {
+		t.Fatalf("%d subscribers left after Watch ctx canceled", n)
+	}
+}
+
+// TestPausedProvider 暂停后 Set / Subscribe 返回错误,Resume 后恢复
+func TestPausedProvider(t *testing.T) {
+	v := NewValueProvider(0, 0)
+	v.Pause()
+	if err := v.Set(1); err == nil {
+		t.Fatal("Set on paused provider: err = nil")
+	}
+	if _, err := v.SubscribeWith(SubscribeOptions{}); err == nil {
+		t.Fatal("SubscribeWith on paused provider: err = nil")
+	}
+	if err := v.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set(2); err != nil || v.Get() != 2 {
+		t.Fatalf("Set after Resume: err = %v, Get = %d", err, v.Get())
+	}
+}
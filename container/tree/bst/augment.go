@@ -0,0 +1,94 @@
+package bst
+
+import "cmp"
+
+// Augmentable 是节点可选实现的接口,用于把 size 之外的「子树聚合值」挂到同一套维护流程上。
+//
+// UpdateSize 在刷新节点 size 之后会检查节点是否实现了 Augmentable,是则调用 Augment。
+// 由于 RotateLeft / RotateRight / RefreshSizeUp 都通过 UpdateSize 刷新节点,
+// 实现者只需在 Augment 中按「左孩子聚合 ⊕ 自身 ⊕ 右孩子聚合」重算即可,
+// 旋转、插入、删除后的聚合值自动保持正确。
+//
+// 约束:Augment 只能读取自身与左右孩子的字段,不得修改树结构。
+type Augmentable interface {
+	Augment()
+}
+
+// Monoid[T, A] 描述一个定义在元素 T 上、取值为 A 的幺半群聚合:
+//   - Identity: 单位元,空子树 / 空区间的聚合值
+//   - Measure: 把单个元素映射为聚合值
+//   - Combine: 结合律成立的二元运算;不要求交换律,实现方保证按中序(升序)从左到右组合
+//
+// 例如 Sum 取 Identity = 0、Measure = 取数值、Combine = 加法;
+// Count-with-predicate 取 Measure = pred(x) ? 1 : 0。
+type Monoid[T, A any] struct {
+	Identity A
+	Measure  func(T) A
+	Combine  func(a, b A) A
+}
+
+// Numeric 是 SumMonoid 支持的数值类型。
+type Numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Extremum 是 Min / Max 聚合的结果;空区间时 Ok 为 false。
+type Extremum[K cmp.Ordered] struct {
+	Value K
+	Ok    bool
+}
+
+// SumMonoid 对 key(x) 求和。
+func SumMonoid[T any, N Numeric](key func(T) N) Monoid[T, N] {
+	return Monoid[T, N]{
+		Identity: 0,
+		Measure:  key,
+		Combine:  func(a, b N) N { return a + b },
+	}
+}
+
+// CountMonoid 统计满足 pred 的元素个数。
+func CountMonoid[T any](pred func(T) bool) Monoid[T, int] {
+	return Monoid[T, int]{
+		Identity: 0,
+		Measure: func(x T) int {
+			if pred(x) {
+				return 1
+			}
+			return 0
+		},
+		Combine: func(a, b int) int { return a + b },
+	}
+}
+
+// MinMonoid 求 key(x) 的最小值。
+func MinMonoid[T any, K cmp.Ordered](key func(T) K) Monoid[T, Extremum[K]] {
+	return extremumMonoid(key, func(a, b K) bool { return a < b })
+}
+
+// MaxMonoid 求 key(x) 的最大值。
+func MaxMonoid[T any, K cmp.Ordered](key func(T) K) Monoid[T, Extremum[K]] {
+	return extremumMonoid(key, func(a, b K) bool { return a > b })
+}
+
+// extremumMonoid 按 better 选出更「优」的一方;空值视为单位元。
+func extremumMonoid[T any, K cmp.Ordered](key func(T) K, better func(a, b K) bool) Monoid[T, Extremum[K]] {
+	return Monoid[T, Extremum[K]]{
+		Identity: Extremum[K]{},
+		Measure:  func(x T) Extremum[K] { return Extremum[K]{Value: key(x), Ok: true} },
+		Combine: func(a, b Extremum[K]) Extremum[K] {
+			switch {
+			case !a.Ok:
+				return b
+			case !b.Ok:
+				return a
+			case better(b.Value, a.Value):
+				return b
+			default:
+				return a
+			}
+		},
+	}
+}
@@ -0,0 +1,126 @@
+package augmented
+
+import (
+	"cmp"
+
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+// Interval 是闭区间 [Start, End] 及其附带的值;要求 Start <= End。
+type Interval[K cmp.Ordered, V any] struct {
+	Start K
+	End   K
+	Value V
+}
+
+// IntervalTree 区间树:按 (Start, End) 排序,每个节点聚合子树内的最大 End,
+// 查询时据此剪掉不可能与查询区间重叠的子树。
+// 允许相同区间重复插入;Delete 按 (Start, End) 删除其中任意一个。
+type IntervalTree[K cmp.Ordered, V any] struct {
+	tree *Tree[Interval[K, V], bst.Extremum[K]]
+}
+
+// NewIntervalTree 创建一棵空区间树。
+func NewIntervalTree[K cmp.Ordered, V any]() *IntervalTree[K, V] {
+	byStartEnd := func(a, b Interval[K, V]) int {
+		if c := cmp.Compare(a.Start, b.Start); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.End, b.End)
+	}
+	maxEnd := bst.MaxMonoid(func(iv Interval[K, V]) K { return iv.End })
+	return &IntervalTree[K, V]{tree: NewTree(byStartEnd, maxEnd)}
+}
+
+// Insert 插入区间 [start, end];start > end 时两端互换。O(log n)。
+func (it *IntervalTree[K, V]) Insert(start, end K, value V) {
+	if start > end {
+		start, end = end, start
+	}
+	it.tree.Insert(Interval[K, V]{Start: start, End: end, Value: value})
+}
+
+// Delete 删除一个端点为 [start, end] 的区间,返回是否删除;与 Insert 一样,start > end 时两端互换。O(log n)。
+func (it *IntervalTree[K, V]) Delete(start, end K) bool {
+	if start > end {
+		start, end = end, start
+	}
+	return it.tree.Delete(Interval[K, V]{Start: start, End: end})
+}
+
+// Size 返回区间个数。
+func (it *IntervalTree[K, V]) Size() int {
+	return it.tree.Size()
+}
+
+// Clear 清空区间树。
+func (it *IntervalTree[K, V]) Clear() {
+	it.tree.Clear()
+}
+
+// All 按 (Start, End) 升序返回全部区间。
+func (it *IntervalTree[K, V]) All() []Interval[K, V] {
+	res := make([]Interval[K, V], 0, it.tree.Size())
+	it.tree.InOrderTraverse(func(iv Interval[K, V]) {
+		res = append(res, iv)
+	})
+	return res
+}
+
+// Overlaps 按 (Start, End) 升序返回全部与闭区间 [start, end] 相交的区间。
+// O(log n + k),k 为结果个数。
+func (it *IntervalTree[K, V]) Overlaps(start, end K) []Interval[K, V] {
+	if start > end {
+		start, end = end, start
+	}
+	res := make([]Interval[K, V], 0)
+	collect_overlaps(it.tree.root, start, end, &res)
+	return res
+}
+
+// Stab 返回全部包含 point 的区间,等价于 Overlaps(point, point)。
+func (it *IntervalTree[K, V]) Stab(point K) []Interval[K, V] {
+	return it.Overlaps(point, point)
+}
+
+// AnyOverlap 返回任意一个与 [start, end] 相交的区间;不存在时返回 (zero, false)。O(log n)。
+func (it *IntervalTree[K, V]) AnyOverlap(start, end K) (Interval[K, V], bool) {
+	if start > end {
+		start, end = end, start
+	}
+	n := it.tree.root
+	for n != nil {
+		iv := n.GetVal()
+		if iv.Start <= end && start <= iv.End {
+			return iv, true
+		}
+		// 左子树的最大 End 够得着 start 时答案若存在必在左侧:
+		// 左子树里 End >= start 的区间的 Start <= n.Start;若它们都在 end 右侧,则右子树更不可能相交
+		if l := n.leftNode(); l != nil && l.agg.Ok && l.agg.Value >= start {
+			n = l
+		} else {
+			n = n.rightNode()
+		}
+	}
+	var zero Interval[K, V]
+	return zero, false
+}
+
+// collect_overlaps 中序收集子树内与 [start, end] 相交的区间:
+//   - 子树的最大 End < start 时整棵剪掉
+//   - 节点 Start > end 时其右子树的 Start 只会更大,剪掉右子树
+func collect_overlaps[K cmp.Ordered, V any](n *augNode[Interval[K, V], bst.Extremum[K]], start, end K, res *[]Interval[K, V]) {
+	if n == nil || !n.agg.Ok || n.agg.Value < start {
+		return
+	}
+	collect_overlaps(n.leftNode(), start, end, res)
+
+	iv := n.GetVal()
+	if iv.Start > end {
+		return
+	}
+	if start <= iv.End {
+		*res = append(*res, iv)
+	}
+	collect_overlaps(n.rightNode(), start, end, res)
+}
@@ -0,0 +1,71 @@
+package augmented
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/leoheung/go-patterns/container/render"
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+// augNode 是带子树聚合值的 treap 节点。
+// agg 由 Augment 维护,bst.UpdateSize 在每次刷新 size 后自动调用,旋转后同样保持正确。
+type augNode[T, A any] struct {
+	*bst.Node[T] // 嵌入指针,所有 BSTNodeInterface method 会被 method promotion 提升到 *augNode[T, A]
+	priority     int
+	agg          A
+	monoid       *bst.Monoid[T, A]
+}
+
+var _ bst.Augmentable = new(augNode[int, int])
+
+func new_aug_node[T, A any](val T, cmp func(a, b T) int, monoid *bst.Monoid[T, A]) *augNode[T, A] {
+	return &augNode[T, A]{
+		Node:     bst.NewNode(val, cmp),
+		priority: rand.Int(),
+		agg:      monoid.Measure(val),
+		monoid:   monoid,
+	}
+}
+
+// Augment implements [bst.Augmentable]:agg = agg(left) ⊕ measure(val) ⊕ agg(right)。
+func (n *augNode[T, A]) Augment() {
+	agg := n.monoid.Combine(aggOf(n.leftNode(), n.monoid), n.monoid.Measure(n.GetVal()))
+	n.agg = n.monoid.Combine(agg, aggOf(n.rightNode(), n.monoid))
+}
+
+// aggOf 返回子树聚合值;空子树返回单位元。
+func aggOf[T, A any](n *augNode[T, A], monoid *bst.Monoid[T, A]) A {
+	if n == nil {
+		return monoid.Identity
+	}
+	return n.agg
+}
+
+func (n *augNode[T, A]) leftNode() *augNode[T, A] {
+	if l := n.GetLeft(); l != nil {
+		return l.(*augNode[T, A])
+	}
+	return nil
+}
+
+func (n *augNode[T, A]) rightNode() *augNode[T, A] {
+	if r := n.GetRight(); r != nil {
+		return r.(*augNode[T, A])
+	}
+	return nil
+}
+
+func (n *augNode[T, A]) parentNode() *augNode[T, A] {
+	if pp := n.GetParent(); pp != nil {
+		return pp.(*augNode[T, A])
+	}
+	return nil
+}
+
+var _ bst.NodeDecorator = new(augNode[int, int])
+
+// Decorate implements [bst.NodeDecorator]:标签追加子树聚合值。
+func (n *augNode[T, A]) Decorate(rn *render.Node) {
+	rn.Label += fmt.Sprintf("\nagg=%v", n.agg)
+}
@@ -0,0 +1,315 @@
+package augmented
+
+import (
+	"io"
+
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+// Tree 是带幺半群聚合的平衡树(treap 实现):
+// 每个节点缓存其子树上 Monoid 的聚合值,借助 bst.Augmentable 在插入、删除与旋转时自动维护,
+// 从而支持 O(log n) 的区间聚合查询 AggregateRange(求和 / 最值 / 条件计数 / ...)。
+type Tree[T, A any] struct {
+	root   *augNode[T, A]
+	cmp    func(a, b T) int
+	monoid *bst.Monoid[T, A]
+}
+
+var _ bst.SelfBalancingBST[int] = new(Tree[int, int])
+
+// NewTree 创建一棵按 cmp 排序、按 monoid 聚合的空树。
+func NewTree[T, A any](cmp func(a, b T) int, monoid bst.Monoid[T, A]) *Tree[T, A] {
+	return &Tree[T, A]{
+		root:   nil,
+		cmp:    cmp,
+		monoid: &monoid,
+	}
+}
+
+// Aggregate 返回整棵树的聚合值;空树返回单位元。O(1)。
+func (t *Tree[T, A]) Aggregate() A {
+	return aggOf(t.root, t.monoid)
+}
+
+// AggregateRange 返回闭区间 [low, high] 内全部元素按升序组合的聚合值;区间为空时返回单位元。
+// 调用方需保证 low ≤ high。O(log n)。
+//
+// 先从根向下找到第一个落在区间内的「分叉节点」s,区间聚合即
+// geq(s.left, low) ⊕ measure(s) ⊕ leq(s.right, high);
+// 两侧各沿一条路径下行,路径上整棵落入区间的子树直接取缓存的聚合值。
+func (t *Tree[T, A]) AggregateRange(low, high T) A {
+	n := t.root
+	for n != nil {
+		switch {
+		case t.cmp(n.GetVal(), low) < 0:
+			n = n.rightNode()
+		case t.cmp(n.GetVal(), high) > 0:
+			n = n.leftNode()
+		default:
+			left := t.aggregateGreaterEqual(n.leftNode(), low)
+			self := t.monoid.Measure(n.GetVal())
+			right := t.aggregateLessEqual(n.rightNode(), high)
+			return t.monoid.Combine(t.monoid.Combine(left, self), right)
+		}
+	}
+	return t.monoid.Identity
+}
+
+// aggregateGreaterEqual 返回子树中全部 >= low 的元素的聚合值。
+func (t *Tree[T, A]) aggregateGreaterEqual(n *augNode[T, A], low T) A {
+	acc := t.monoid.Identity
+	for n != nil {
+		if t.cmp(n.GetVal(), low) < 0 {
+			// n 与其左子树都 < low
+			n = n.rightNode()
+			continue
+		}
+		// n 与其右子树都 >= low;结果按升序,先组合的部分在右侧
+		right := t.monoid.Combine(t.monoid.Measure(n.GetVal()), aggOf(n.rightNode(), t.monoid))
+		acc = t.monoid.Combine(right, acc)
+		n = n.leftNode()
+	}
+	return acc
+}
+
+// aggregateLessEqual 返回子树中全部 <= high 的元素的聚合值。
+func (t *Tree[T, A]) aggregateLessEqual(n *augNode[T, A], high T) A {
+	acc := t.monoid.Identity
+	for n != nil {
+		if t.cmp(n.GetVal(), high) > 0 {
+			// n 与其右子树都 > high
+			n = n.leftNode()
+			continue
+		}
+		// n 与其左子树都 <= high
+		left := t.monoid.Combine(aggOf(n.leftNode(), t.monoid), t.monoid.Measure(n.GetVal()))
+		acc = t.monoid.Combine(acc, left)
+		n = n.rightNode()
+	}
+	return acc
+}
+
+// Clear implements [bst.SelfBalancingBST].
+func (t *Tree[T, A]) Clear() {
+	t.root = nil
+}
+
+// Delete implements [bst.SelfBalancingBST].
+func (t *Tree[T, A]) Delete(item T) bool {
+	ptr, ok := bst.Get(t.root, item)
+	if !ok {
+		return false
+	}
+	return delete_rec(ptr.(*augNode[T, A]), &t.root)
+}
+
+// Get implements [bst.SelfBalancingBST].
+func (t *Tree[T, A]) Get(item T) (T, bool) {
+	var zero T
+	ptr, ok := bst.Get(t.root, item)
+
+	if ok {
+		return ptr.GetVal(), true
+	} else {
+		return zero, false
+	}
+}
+
+// InOrderTraverse 中序遍历，按 cmp 升序对每个元素调用 fn。
+func (t *Tree[T, A]) InOrderTraverse(fn func(T)) {
+	bst.InOrderTraverse(t.root, fn)
+}
+
+// PreorderTraverse 前序遍历。
+func (t *Tree[T, A]) PreorderTraverse(fn func(T)) {
+	bst.PreorderTraverse(t.root, fn)
+}
+
+// PostorderTraverse 后序遍历。
+func (t *Tree[T, A]) PostorderTraverse(fn func(T)) {
+	bst.PostorderTraverse(t.root, fn)
+}
+
+// IsEmpty implements [bst.SelfBalancingBST].
+func (t *Tree[T, A]) IsEmpty() bool {
+	return t.root == nil
+}
+
+// IsLessThan implements [bst.SelfBalancingBST].
+func (t *Tree[T, A]) IsLessThan() func(a T, b T) int {
+	return t.cmp
+}
+
+// Max 返回树中最大元素；空树返回 (zero, false)。
+func (t *Tree[T, A]) Max() (T, bool) {
+	return bst.Max(t.root)
+}
+
+// Min 返回树中最小元素；空树返回 (zero, false)。
+func (t *Tree[T, A]) Min() (T, bool) {
+	return bst.Min(t.root)
+}
+
+// Predecessor 返回严格小于 item 的最大元素；不存在时返回 (zero, false)。
+func (t *Tree[T, A]) Predecessor(item T) (T, bool) {
+	return bst.Predecessor(t.root, item)
+}
+
+// Insert 按 BST 规则挂接新节点（相等元素走左），沿父链刷新 size 与聚合值，
+// 再经 reorganize_by_priority 上浮维护 treap 堆性质（旋转会自动刷新局部聚合值）。
+func (t *Tree[T, A]) Insert(item T) {
+	n := new_aug_node(item, t.cmp, t.monoid)
+	if t.root == nil {
+		t.root = n
+		return
+	}
+	insert_rec(t.root, n)
+	reorganize_by_priority(n, &t.root)
+}
+
+// Update 定位与 item 相等的节点，调用 callback 修改内容，随后按需重排。
+// 仍然有序时只沿父链刷新聚合值（callback 可能改变了参与 Measure 的字段）；
+// 否则删除并重新插入。
+func (t *Tree[T, A]) Update(item T, callback func(item T)) {
+	ptr, ok := bst.Get(t.root, item)
+	if !ok {
+		return // 不存在则不回调、不新增
+	}
+	an, _ := ptr.(*augNode[T, A])
+	if an == nil {
+		return
+	}
+	callback(an.GetVal())
+
+	if bst.IsOrderedNode(an) {
+		bst.RefreshSizeUp[T](an)
+		return
+	}
+	val := an.GetVal()
+	delete_rec(an, &t.root)
+	t.Insert(val)
+}
+
+// RangeVisit 闭区间 [low, high] 升序遍历；调用方需保证 low ≤ high。
+func (t *Tree[T, A]) RangeVisit(low T, high T, callback func(T)) {
+	bst.RangeVisit(t.root, low, high, callback)
+}
+
+// Rank 返回严格小于 item 的元素个数（0-based）。
+func (t *Tree[T, A]) Rank(item T) int {
+	return bst.Rank(t.root, item)
+}
+
+// Select 返回第 rank 小（0-based）元素；越界返回 (zero, false)。
+func (t *Tree[T, A]) Select(rank int) (T, bool) {
+	return bst.Select(t.root, rank)
+}
+
+// Size implements [bst.SelfBalancingBST].
+func (t *Tree[T, A]) Size() int {
+	if t.root == nil {
+		return 0
+	}
+	return t.root.GetSize()
+}
+
+// Successor 返回严格大于 item 的最小元素；不存在时返回 (zero, false)。
+func (t *Tree[T, A]) Successor(item T) (T, bool) {
+	return bst.Successor(t.root, item)
+}
+
+// DrawTree 把整棵树以带缩进的树形文本输出到 out（仅打印节点值，用于观察结构）。
+func (t *Tree[T, A]) DrawTree(out io.Writer) {
+	bst.DrawTree(t.root, out)
+}
+
+// DrawDOT implements [bst.SelfBalancingBST].
+func (t *Tree[T, A]) DrawDOT(out io.Writer) error {
+	return bst.DrawDOT(t.root, out)
+}
+
+// DrawMermaid implements [bst.SelfBalancingBST].
+func (t *Tree[T, A]) DrawMermaid(out io.Writer) error {
+	return bst.DrawMermaid(t.root, out)
+}
+
+// insert_rec 把新节点 nn 挂到以 cur 为根的子树中；相等元素走左，允许重复共存。
+func insert_rec[T, A any](cur, nn *augNode[T, A]) {
+	if cur.CompareFn()(cur.GetVal(), nn.GetVal()) >= 0 {
+		if cur.leftNode() == nil {
+			cur.SetLeft(nn)
+			nn.SetParent(cur)
+			bst.RefreshSizeUp[T](cur)
+		} else {
+			insert_rec(cur.leftNode(), nn)
+		}
+	} else {
+		if cur.rightNode() == nil {
+			cur.SetRight(nn)
+			nn.SetParent(cur)
+			bst.RefreshSizeUp[T](cur)
+		} else {
+			insert_rec(cur.rightNode(), nn)
+		}
+	}
+}
+
+// reorganize_by_priority 把 p 沿父链上浮,直到父亲的 priority 不低于 p。
+func reorganize_by_priority[T, A any](p *augNode[T, A], rootPtr **augNode[T, A]) {
+	for pp := p.parentNode(); pp != nil && pp.priority < p.priority; pp = p.parentNode() {
+		if bst.IsLeftChild[T](pp, p) {
+			bst.RotateRight[T](pp)
+		} else {
+			bst.RotateLeft[T](pp)
+		}
+	}
+	if p.parentNode() == nil {
+		*rootPtr = p
+	}
+}
+
+// delete_rec 把 p 按孩子 priority 旋转下沉至至多一个孩子,再摘除并沿父链刷新。
+func delete_rec[T, A any](p *augNode[T, A], rootPtr **augNode[T, A]) bool {
+	for p.leftNode() != nil && p.rightNode() != nil {
+		var sub bst.BSTNodeInterface[T]
+		if p.leftNode().priority >= p.rightNode().priority {
+			sub = bst.RotateRight[T](p)
+		} else {
+			sub = bst.RotateLeft[T](p)
+		}
+		if sub.GetParent() == nil {
+			*rootPtr = sub.(*augNode[T, A])
+		}
+	}
+
+	var child bst.BSTNodeInterface[T]
+	if l := p.leftNode(); l != nil {
+		child = l
+	} else if r := p.rightNode(); r != nil {
+		child = r
+	}
+
+	pp := p.parentNode()
+	if child != nil {
+		if pp != nil {
+			child.SetParent(pp)
+		} else {
+			child.SetParent(nil)
+		}
+	}
+	if pp == nil {
+		if child == nil {
+			*rootPtr = nil
+		} else {
+			*rootPtr = child.(*augNode[T, A])
+		}
+		return true
+	}
+	if bst.IsLeftChild[T](pp, p) {
+		pp.SetLeft(child)
+	} else {
+		pp.SetRight(child)
+	}
+	bst.RefreshSizeUp[T](pp)
+	return true
+}
@@ -0,0 +1,304 @@
+package avl
+
+import (
+	"io"
+
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+type AVL[T any] struct {
+	root *avlNode[T]
+	cmp  func(a, b T) int
+}
+
+var _ bst.SelfBalancingBST[int] = new(AVL[int])
+
+func NewAVL[T any](cmp func(a, b T) int) *AVL[T] {
+	return &AVL[T]{
+		root: nil,
+		cmp:  cmp,
+	}
+}
+
+// Clear implements [bst.SelfBalancingBST].
+func (t *AVL[T]) Clear() {
+	t.root = nil
+}
+
+// Delete implements [bst.SelfBalancingBST].
+func (t *AVL[T]) Delete(item T) bool {
+	ptr, ok := bst.Get(t.root, item)
+	if !ok {
+		return false
+	}
+	delete_node(ptr.(*avlNode[T]), &t.root)
+	return true
+}
+
+// Get implements [bst.SelfBalancingBST].
+func (t *AVL[T]) Get(item T) (T, bool) {
+	var zero T
+	ptr, ok := bst.Get(t.root, item)
+
+	if ok {
+		return ptr.GetVal(), true
+	} else {
+		return zero, false
+	}
+}
+
+// InOrderTraverse 中序遍历，按 cmp 升序对每个元素调用 fn。
+func (t *AVL[T]) InOrderTraverse(fn func(T)) {
+	bst.InOrderTraverse(t.root, fn)
+}
+
+// PreorderTraverse 前序遍历。
+func (t *AVL[T]) PreorderTraverse(fn func(T)) {
+	bst.PreorderTraverse(t.root, fn)
+}
+
+// PostorderTraverse 后序遍历。
+func (t *AVL[T]) PostorderTraverse(fn func(T)) {
+	bst.PostorderTraverse(t.root, fn)
+}
+
+// IsEmpty implements [bst.SelfBalancingBST].
+func (t *AVL[T]) IsEmpty() bool {
+	return t.root == nil
+}
+
+// IsLessThan implements [bst.SelfBalancingBST].
+func (t *AVL[T]) IsLessThan() func(a T, b T) int {
+	return t.cmp
+}
+
+// Max 返回树中最大元素；空树返回 (zero, false)。
+func (t *AVL[T]) Max() (T, bool) {
+	return bst.Max(t.root)
+}
+
+// Min 返回树中最小元素；空树返回 (zero, false)。
+func (t *AVL[T]) Min() (T, bool) {
+	return bst.Min(t.root)
+}
+
+// Predecessor 返回严格小于 item 的最大元素；不存在时返回 (zero, false)。
+func (t *AVL[T]) Predecessor(item T) (T, bool) {
+	return bst.Predecessor(t.root, item)
+}
+
+// Insert 按 BST 规则定位插入位置（相等元素走左），挂接后自新节点的父亲起
+// 沿父链回溯，刷新 height / size 并在失衡处旋转。
+func (t *AVL[T]) Insert(item T) {
+	n := new_avl_node(item, t.cmp)
+	if t.root == nil {
+		t.root = n
+		return
+	}
+	insert_rec(t.root, n)
+	rebalance_up(n.parentNode(), &t.root)
+}
+
+// Update 定位与 item 相等的节点，调用 callback 修改内容，随后按需重排。
+// 仅当修改后不再满足 BST 有序不变量时才删除并重新插入。
+func (t *AVL[T]) Update(item T, callback func(item T)) {
+	ptr, ok := bst.Get(t.root, item)
+	if !ok {
+		return // 不存在则不回调、不新增
+	}
+	an, _ := ptr.(*avlNode[T])
+	if an == nil {
+		return
+	}
+	callback(an.GetVal())
+
+	if bst.IsOrderedNode(an) {
+		return // 仍有序，免重排
+	}
+	// 乱序：按节点引用删除 + 重新插入。
+	// 不能用 Delete(item) 重新定位：callback 已改变排序键，按新键搜索无法命中该节点。
+	val := an.GetVal()
+	delete_node(an, &t.root)
+	t.Insert(val)
+}
+
+// RangeVisit 闭区间 [low, high] 升序遍历；调用方需保证 low ≤ high。
+func (t *AVL[T]) RangeVisit(low T, high T, callback func(T)) {
+	bst.RangeVisit(t.root, low, high, callback)
+}
+
+// Rank 返回严格小于 item 的元素个数（0-based）。
+func (t *AVL[T]) Rank(item T) int {
+	return bst.Rank(t.root, item)
+}
+
+// Select 返回第 rank 小（0-based）元素；越界返回 (zero, false)。
+func (t *AVL[T]) Select(rank int) (T, bool) {
+	return bst.Select(t.root, rank)
+}
+
+// Size implements [bst.SelfBalancingBST].
+func (t *AVL[T]) Size() int {
+	if t.root == nil {
+		return 0
+	}
+	return t.root.GetSize()
+}
+
+// Height 返回树高；空树为 0，单节点为 1。
+func (t *AVL[T]) Height() int {
+	return heightOf(t.root)
+}
+
+// Successor 返回严格大于 item 的最小元素；不存在时返回 (zero, false)。
+func (t *AVL[T]) Successor(item T) (T, bool) {
+	return bst.Successor(t.root, item)
+}
+
+// DrawTree 把整棵树以带缩进的树形文本输出到 out（仅打印节点值，用于观察结构）。
+func (t *AVL[T]) DrawTree(out io.Writer) {
+	bst.DrawTree(t.root, out)
+}
+
+// DrawDOT implements [bst.SelfBalancingBST].
+func (t *AVL[T]) DrawDOT(out io.Writer) error {
+	return bst.DrawDOT(t.root, out)
+}
+
+// DrawMermaid implements [bst.SelfBalancingBST].
+func (t *AVL[T]) DrawMermaid(out io.Writer) error {
+	return bst.DrawMermaid(t.root, out)
+}
+
+// insert_rec 把新节点 nn 挂到以 cur 为根的子树中；相等元素走左，允许重复共存。
+// 只负责挂接，height / size 由调用方通过 rebalance_up 统一刷新。
+func insert_rec[T any](cur, nn *avlNode[T]) {
+	if cur.CompareFn()(cur.GetVal(), nn.GetVal()) >= 0 {
+		if cur.leftNode() == nil {
+			cur.SetLeft(nn)
+			nn.SetParent(cur)
+		} else {
+			insert_rec(cur.leftNode(), nn)
+		}
+	} else {
+		if cur.rightNode() == nil {
+			cur.SetRight(nn)
+			nn.SetParent(cur)
+		} else {
+			insert_rec(cur.rightNode(), nn)
+		}
+	}
+}
+
+// delete_node 按节点引用摘除 p，随后自结构发生变化的最低点回溯重平衡。
+//
+// 双孩子时用「中序前驱」(左子树最大节点)顶替 p:相等元素插入时挂在左侧,
+// 左子树最大节点 pred 满足 左子树其余节点 <= pred <= 右子树,顶替后中序顺序不变。
+func delete_node[T any](p *avlNode[T], rootPtr **avlNode[T]) {
+	pl := p.leftNode()
+	pr := p.rightNode()
+
+	if pl == nil || pr == nil {
+		// case 1 / 2: 至多一个孩子,孩子直接顶替 p
+		child := pl
+		if child == nil {
+			child = pr
+		}
+		pp := p.parentNode()
+		replace_child(pp, p, child, rootPtr)
+		rebalance_up(pp, rootPtr)
+		return
+	}
+
+	// case 3: 双孩子,找中序前驱
+	pred := pl
+	for pred.rightNode() != nil {
+		pred = pred.rightNode()
+	}
+
+	// 回溯起点:pred 原来的父亲;若 pred 就是 p 的左孩子,则从 pred 自身开始
+	start := pred.parentNode()
+	if pred != pl {
+		// pred 没有右孩子,用它的左孩子顶替它原来的位置
+		replace_child(start, pred, pred.leftNode(), rootPtr)
+		pred.SetLeft(pl)
+		pl.SetParent(pred)
+	} else {
+		start = pred
+	}
+
+	pred.SetRight(pr)
+	pr.SetParent(pred)
+	replace_child(p.parentNode(), p, pred, rootPtr)
+	pred.height = p.height
+
+	rebalance_up(start, rootPtr)
+}
+
+// replace_child 把 pp 下的孩子 old 替换为 nn(nn 可为 nil);pp 为 nil 时 nn 成为新根。
+// nn 为 nil 时必须写入 nil 接口,不能写入 (*avlNode[T])(nil),否则 GetLeft() != nil 判断失效。
+func replace_child[T any](pp, old, nn *avlNode[T], rootPtr **avlNode[T]) {
+	var child bst.BSTNodeInterface[T]
+	if nn != nil {
+		child = nn
+		if pp != nil {
+			nn.SetParent(pp)
+		} else {
+			nn.SetParent(nil)
+		}
+	}
+
+	if pp == nil {
+		*rootPtr = nn
+	} else if bst.IsLeftChild[T](pp, old) {
+		pp.SetLeft(child)
+	} else {
+		pp.SetRight(child)
+	}
+}
+
+// rebalance_up 自 n 起沿父链向上:刷新 height / size,遇到失衡节点按 LL / LR / RR / RL 旋转。
+// 旋转不改变子树总 size,但会改变局部 height,所以必须一直回溯到根。
+func rebalance_up[T any](n *avlNode[T], rootPtr **avlNode[T]) {
+	for n != nil {
+		n.updateHeight()
+		bst.UpdateSize[T](n)
+
+		sub := n
+		switch bf := n.balanceFactor(); {
+		case bf > 1:
+			// 左高:LR 先把左孩子左旋成 LL
+			if n.leftNode().balanceFactor() < 0 {
+				rotate_left(n.leftNode())
+			}
+			sub = rotate_right(n)
+		case bf < -1:
+			// 右高:RL 先把右孩子右旋成 RR
+			if n.rightNode().balanceFactor() > 0 {
+				rotate_right(n.rightNode())
+			}
+			sub = rotate_left(n)
+		}
+
+		if sub.parentNode() == nil {
+			*rootPtr = sub
+		}
+		n = sub.parentNode()
+	}
+}
+
+// rotate_left 调用 bst.RotateLeft(已同步 parent 与 size),再自下而上刷新两个节点的 height。
+func rotate_left[T any](p *avlNode[T]) *avlNode[T] {
+	r := bst.RotateLeft[T](p).(*avlNode[T])
+	p.updateHeight()
+	r.updateHeight()
+	return r
+}
+
+// rotate_right 是 rotate_left 的镜像。
+func rotate_right[T any](p *avlNode[T]) *avlNode[T] {
+	l := bst.RotateRight[T](p).(*avlNode[T])
+	p.updateHeight()
+	l.updateHeight()
+	return l
+}
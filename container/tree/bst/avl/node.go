@@ -0,0 +1,66 @@
+package avl
+
+import (
+	"fmt"
+
+	"github.com/leoheung/go-patterns/container/render"
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+type avlNode[T any] struct {
+	*bst.Node[T] // 嵌入指针,所有 BSTNodeInterface method 会被 method promotion 提升到 *avlNode[T]
+	height       int
+}
+
+func new_avl_node[T any](val T, cmp func(a, b T) int) *avlNode[T] {
+	return &avlNode[T]{
+		bst.NewNode(val, cmp),
+		1,
+	}
+}
+
+func (n *avlNode[T]) leftNode() *avlNode[T] {
+	if l := n.GetLeft(); l != nil {
+		return l.(*avlNode[T])
+	}
+	return nil
+}
+
+func (n *avlNode[T]) rightNode() *avlNode[T] {
+	if r := n.GetRight(); r != nil {
+		return r.(*avlNode[T])
+	}
+	return nil
+}
+
+func (n *avlNode[T]) parentNode() *avlNode[T] {
+	if pp := n.GetParent(); pp != nil {
+		return pp.(*avlNode[T])
+	}
+	return nil
+}
+
+// heightOf 返回子树高度；空子树高度为 0，叶子高度为 1。
+func heightOf[T any](n *avlNode[T]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// updateHeight 按左右孩子高度重算 n 自身的高度。
+func (n *avlNode[T]) updateHeight() {
+	n.height = 1 + max(heightOf(n.leftNode()), heightOf(n.rightNode()))
+}
+
+// balanceFactor 返回左子树高度减右子树高度；AVL 要求取值在 [-1, 1]。
+func (n *avlNode[T]) balanceFactor() int {
+	return heightOf(n.leftNode()) - heightOf(n.rightNode())
+}
+
+var _ bst.NodeDecorator = new(avlNode[int])
+
+// Decorate implements [bst.NodeDecorator]:标签追加节点高度。
+func (n *avlNode[T]) Decorate(rn *render.Node) {
+	rn.Label += fmt.Sprintf("\nh=%d", n.height)
+}
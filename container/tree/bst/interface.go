@@ -365,4 +365,15 @@ type SelfBalancingBST[T any] interface {
 	// 1. 调试平衡树插入/删除后的结构；
 	// 2. demo 中每步操作后打印整棵树。
 	DrawTree(out io.Writer)
+
+	// DrawDOT / DrawMermaid
+	// 把整棵树输出为 Graphviz DOT / Mermaid flowchart 文本，适合节点较多、需要贴进文档的场景。
+	// 节点实现了 NodeDecorator 时会附带实现特有的信息（如 treap 的 priority、红黑树的颜色）。
+	// 空树输出一张空图；返回值为写入 out 时的错误。
+	//
+	// 示例场景：
+	// 1. 把 DOT 交给 `dot -Tsvg` 渲染，调试上百个节点的树形；
+	// 2. 把 Mermaid 直接嵌入 Markdown 设计文档。
+	DrawDOT(out io.Writer) error
+	DrawMermaid(out io.Writer) error
 }
@@ -0,0 +1,58 @@
+package rbtree
+
+import (
+	"github.com/leoheung/go-patterns/container/render"
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+type rbNode[T any] struct {
+	*bst.Node[T] // 嵌入指针,所有 BSTNodeInterface method 会被 method promotion 提升到 *rbNode[T]
+	red          bool
+}
+
+// new_rb_node 新节点一律为红色,插入后由 insert_fixup 修正。
+func new_rb_node[T any](val T, cmp func(a, b T) int) *rbNode[T] {
+	return &rbNode[T]{
+		bst.NewNode(val, cmp),
+		true,
+	}
+}
+
+func (n *rbNode[T]) leftNode() *rbNode[T] {
+	if l := n.GetLeft(); l != nil {
+		return l.(*rbNode[T])
+	}
+	return nil
+}
+
+func (n *rbNode[T]) rightNode() *rbNode[T] {
+	if r := n.GetRight(); r != nil {
+		return r.(*rbNode[T])
+	}
+	return nil
+}
+
+func (n *rbNode[T]) parentNode() *rbNode[T] {
+	if pp := n.GetParent(); pp != nil {
+		return pp.(*rbNode[T])
+	}
+	return nil
+}
+
+// isRed 空节点(nil 叶子)视为黑色。
+func isRed[T any](n *rbNode[T]) bool {
+	return n != nil && n.red
+}
+
+var _ bst.NodeDecorator = new(rbNode[int])
+
+// Decorate implements [bst.NodeDecorator]:按节点颜色填充。
+func (n *rbNode[T]) Decorate(rn *render.Node) {
+	rn.Shape = render.Circle
+	rn.FontColor = "white"
+	if n.red {
+		rn.Fill = "red"
+	} else {
+		rn.Fill = "black"
+	}
+}
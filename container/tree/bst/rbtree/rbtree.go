@@ -0,0 +1,451 @@
+package rbtree
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+// RBTree 红黑树(经典 CLRS 版本,带父指针)。
+// 与 AVL 相比,插入最多 2 次旋转、删除最多 3 次旋转,适合写多读少的有序索引。
+type RBTree[T any] struct {
+	root *rbNode[T]
+	cmp  func(a, b T) int
+}
+
+var _ bst.SelfBalancingBST[int] = new(RBTree[int])
+
+func NewRBTree[T any](cmp func(a, b T) int) *RBTree[T] {
+	return &RBTree[T]{
+		root: nil,
+		cmp:  cmp,
+	}
+}
+
+// Clear implements [bst.SelfBalancingBST].
+func (t *RBTree[T]) Clear() {
+	t.root = nil
+}
+
+// Delete implements [bst.SelfBalancingBST].
+func (t *RBTree[T]) Delete(item T) bool {
+	ptr, ok := bst.Get(t.root, item)
+	if !ok {
+		return false
+	}
+	t.deleteNode(ptr.(*rbNode[T]))
+	return true
+}
+
+// Get implements [bst.SelfBalancingBST].
+func (t *RBTree[T]) Get(item T) (T, bool) {
+	var zero T
+	ptr, ok := bst.Get(t.root, item)
+
+	if ok {
+		return ptr.GetVal(), true
+	} else {
+		return zero, false
+	}
+}
+
+// InOrderTraverse 中序遍历，按 cmp 升序对每个元素调用 fn。
+func (t *RBTree[T]) InOrderTraverse(fn func(T)) {
+	bst.InOrderTraverse(t.root, fn)
+}
+
+// PreorderTraverse 前序遍历。
+func (t *RBTree[T]) PreorderTraverse(fn func(T)) {
+	bst.PreorderTraverse(t.root, fn)
+}
+
+// PostorderTraverse 后序遍历。
+func (t *RBTree[T]) PostorderTraverse(fn func(T)) {
+	bst.PostorderTraverse(t.root, fn)
+}
+
+// IsEmpty implements [bst.SelfBalancingBST].
+func (t *RBTree[T]) IsEmpty() bool {
+	return t.root == nil
+}
+
+// IsLessThan implements [bst.SelfBalancingBST].
+func (t *RBTree[T]) IsLessThan() func(a T, b T) int {
+	return t.cmp
+}
+
+// Max 返回树中最大元素；空树返回 (zero, false)。
+func (t *RBTree[T]) Max() (T, bool) {
+	return bst.Max(t.root)
+}
+
+// Min 返回树中最小元素；空树返回 (zero, false)。
+func (t *RBTree[T]) Min() (T, bool) {
+	return bst.Min(t.root)
+}
+
+// Predecessor 返回严格小于 item 的最大元素；不存在时返回 (zero, false)。
+func (t *RBTree[T]) Predecessor(item T) (T, bool) {
+	return bst.Predecessor(t.root, item)
+}
+
+// Insert 按 BST 规则挂接红色新节点（相等元素走左），沿父链刷新 size，
+// 再经 insert_fixup 修复「红节点不能有红孩子」与根为黑的性质。
+func (t *RBTree[T]) Insert(item T) {
+	n := new_rb_node(item, t.cmp)
+	if t.root == nil {
+		n.red = false
+		t.root = n
+		return
+	}
+	insert_rec(t.root, n)
+	t.insertFixup(n)
+}
+
+// Update 定位与 item 相等的节点，调用 callback 修改内容，随后按需重排。
+// 仅当修改后不再满足 BST 有序不变量时才删除并重新插入。
+func (t *RBTree[T]) Update(item T, callback func(item T)) {
+	ptr, ok := bst.Get(t.root, item)
+	if !ok {
+		return // 不存在则不回调、不新增
+	}
+	rn, _ := ptr.(*rbNode[T])
+	if rn == nil {
+		return
+	}
+	callback(rn.GetVal())
+
+	if bst.IsOrderedNode(rn) {
+		return // 仍有序，免重排
+	}
+	// 乱序：按节点引用删除 + 重新插入。
+	// 不能用 Delete(item) 重新定位：callback 已改变排序键，按新键搜索无法命中该节点。
+	val := rn.GetVal()
+	t.deleteNode(rn)
+	t.Insert(val)
+}
+
+// RangeVisit 闭区间 [low, high] 升序遍历；调用方需保证 low ≤ high。
+func (t *RBTree[T]) RangeVisit(low T, high T, callback func(T)) {
+	bst.RangeVisit(t.root, low, high, callback)
+}
+
+// Rank 返回严格小于 item 的元素个数（0-based）。
+func (t *RBTree[T]) Rank(item T) int {
+	return bst.Rank(t.root, item)
+}
+
+// Select 返回第 rank 小（0-based）元素；越界返回 (zero, false)。
+func (t *RBTree[T]) Select(rank int) (T, bool) {
+	return bst.Select(t.root, rank)
+}
+
+// Size implements [bst.SelfBalancingBST].
+func (t *RBTree[T]) Size() int {
+	if t.root == nil {
+		return 0
+	}
+	return t.root.GetSize()
+}
+
+// Successor 返回严格大于 item 的最小元素；不存在时返回 (zero, false)。
+func (t *RBTree[T]) Successor(item T) (T, bool) {
+	return bst.Successor(t.root, item)
+}
+
+// DrawTree 把整棵树以带缩进的树形文本输出到 out（仅打印节点值，用于观察结构）。
+func (t *RBTree[T]) DrawTree(out io.Writer) {
+	bst.DrawTree(t.root, out)
+}
+
+// DrawDOT implements [bst.SelfBalancingBST].
+func (t *RBTree[T]) DrawDOT(out io.Writer) error {
+	return bst.DrawDOT(t.root, out)
+}
+
+// DrawMermaid implements [bst.SelfBalancingBST].
+func (t *RBTree[T]) DrawMermaid(out io.Writer) error {
+	return bst.DrawMermaid(t.root, out)
+}
+
+// CheckInvariants 校验整棵树的全部不变量，全部满足时返回 nil：
+//  1. 根为黑色
+//  2. 红节点的孩子均为黑色
+//  3. 任意节点到其所有 nil 叶子的路径上黑色节点数相同
+//  4. 中序序列单调不降（插入时相等元素走左，但旋转可能把相等元素换到右侧，
+//     因此这里只要求 左 <= 节点 <= 右，与 bst.RangeVisit 的处理一致）
+//  5. 父指针与孩子指针互相一致，根的父指针为 nil
+//  6. 每个节点的 size 等于左右子树 size 之和加 1
+//
+// 用于测试与调试；复杂度 O(n)。
+func (t *RBTree[T]) CheckInvariants() error {
+	if t.root == nil {
+		return nil
+	}
+	if t.root.GetParent() != nil {
+		return fmt.Errorf("root has a parent")
+	}
+	if t.root.red {
+		return fmt.Errorf("root is red")
+	}
+	if _, err := check_rec(t.root); err != nil {
+		return err
+	}
+
+	var prev *T
+	var err error
+	bst.InOrderTraverse(t.root, func(v T) {
+		if err == nil && prev != nil && t.cmp(*prev, v) > 0 {
+			err = fmt.Errorf("in-order sequence decreases at %v -> %v", *prev, v)
+		}
+		prev = &v
+	})
+	return err
+}
+
+// check_rec 返回以 n 为根子树的黑高（nil 叶子计 1）。
+func check_rec[T any](n *rbNode[T]) (int, error) {
+	if n == nil {
+		return 1, nil
+	}
+
+	l, r := n.leftNode(), n.rightNode()
+	if n.red && (isRed(l) || isRed(r)) {
+		return 0, fmt.Errorf("red node %v has a red child", n.GetVal())
+	}
+	size := 1
+	for _, c := range []*rbNode[T]{l, r} {
+		if c == nil {
+			continue
+		}
+		if c.parentNode() != n {
+			return 0, fmt.Errorf("node %v has a stale parent pointer", c.GetVal())
+		}
+		size += c.GetSize()
+	}
+	if size != n.GetSize() {
+		return 0, fmt.Errorf("node %v has size %d, want %d", n.GetVal(), n.GetSize(), size)
+	}
+
+	lh, err := check_rec(l)
+	if err != nil {
+		return 0, err
+	}
+	rh, err := check_rec(r)
+	if err != nil {
+		return 0, err
+	}
+	if lh != rh {
+		return 0, fmt.Errorf("node %v has black heights %d (left) and %d (right)", n.GetVal(), lh, rh)
+	}
+
+	if !n.red {
+		lh++
+	}
+	return lh, nil
+}
+
+// insert_rec 把新节点 nn 挂到以 cur 为根的子树中；相等元素走左，允许重复共存。
+func insert_rec[T any](cur, nn *rbNode[T]) {
+	if cur.CompareFn()(cur.GetVal(), nn.GetVal()) >= 0 {
+		if cur.leftNode() == nil {
+			cur.SetLeft(nn)
+			nn.SetParent(cur)
+			bst.RefreshSizeUp[T](cur)
+		} else {
+			insert_rec(cur.leftNode(), nn)
+		}
+	} else {
+		if cur.rightNode() == nil {
+			cur.SetRight(nn)
+			nn.SetParent(cur)
+			bst.RefreshSizeUp[T](cur)
+		} else {
+			insert_rec(cur.rightNode(), nn)
+		}
+	}
+}
+
+// insertFixup 自红色新节点 n 向上修复「红-红」冲突：
+//   - 叔叔为红:父、叔染黑,祖父染红,冲突上移到祖父
+//   - 叔叔为黑:先把「之」字形转成「一」字形,再旋转祖父并交换颜色,结束
+func (t *RBTree[T]) insertFixup(n *rbNode[T]) {
+	for isRed(n.parentNode()) {
+		p := n.parentNode()
+		g := p.parentNode() // 父为红则必不是根,祖父必然存在
+
+		if p == g.leftNode() {
+			if u := g.rightNode(); isRed(u) {
+				p.red, u.red, g.red = false, false, true
+				n = g
+				continue
+			}
+			if n == p.rightNode() {
+				n = p
+				t.rotateLeft(n)
+				p = n.parentNode()
+			}
+			p.red, g.red = false, true
+			t.rotateRight(g)
+		} else {
+			if u := g.leftNode(); isRed(u) {
+				p.red, u.red, g.red = false, false, true
+				n = g
+				continue
+			}
+			if n == p.leftNode() {
+				n = p
+				t.rotateRight(n)
+				p = n.parentNode()
+			}
+			p.red, g.red = false, true
+			t.rotateLeft(g)
+		}
+	}
+	t.root.red = false
+}
+
+// deleteNode 按节点引用摘除 z。
+//
+// 双孩子时用「中序前驱」(左子树最大节点)顶替 z,而不是 CLRS 的中序后继:
+// 相等元素插入时挂在左侧,左子树最大节点 y 满足 左子树其余节点 <= y <= 右子树,
+// 顶替后中序顺序不变。
+//
+// 被真正移出原位置的节点若为黑色,会让经过 x 的路径少一个黑节点,
+// 交给 deleteFixup 修复;x 可能是 nil 叶子,因此额外记录它的父亲 xParent。
+func (t *RBTree[T]) deleteNode(z *rbNode[T]) {
+	var x, xParent *rbNode[T]
+	removedRed := z.red
+
+	switch {
+	case z.leftNode() == nil:
+		x, xParent = z.rightNode(), z.parentNode()
+		t.transplant(z, x)
+	case z.rightNode() == nil:
+		x, xParent = z.leftNode(), z.parentNode()
+		t.transplant(z, x)
+	default:
+		y := z.leftNode()
+		for y.rightNode() != nil {
+			y = y.rightNode()
+		}
+		removedRed = y.red
+		x = y.leftNode()
+
+		if y.parentNode() == z {
+			xParent = y
+		} else {
+			xParent = y.parentNode()
+			t.transplant(y, x)
+			y.SetLeft(z.GetLeft())
+			y.GetLeft().SetParent(y)
+		}
+		t.transplant(z, y)
+		y.SetRight(z.GetRight())
+		y.GetRight().SetParent(y)
+		y.red = z.red
+	}
+
+	// 结构变化的最低点是 xParent,沿父链刷新 size
+	if xParent != nil {
+		bst.RefreshSizeUp[T](xParent)
+	}
+
+	if !removedRed {
+		t.deleteFixup(x, xParent)
+	}
+}
+
+// deleteFixup 修复 x 所在路径缺少的一个黑节点(x 带「额外的黑」):
+//   - 兄弟为红:旋转父亲使兄弟变黑,转入下列情况
+//   - 兄弟的两个孩子都黑:兄弟染红,额外的黑上移到父亲
+//   - 兄弟远端孩子为黑:旋转兄弟,使远端孩子变红
+//   - 兄弟远端孩子为红:旋转父亲并重新着色,结束
+func (t *RBTree[T]) deleteFixup(x, parent *rbNode[T]) {
+	for x != t.root && !isRed(x) {
+		// x 可能为 nil,用父亲的孩子指针判断方向;此时兄弟必然非空
+		if x == parent.leftNode() {
+			w := parent.rightNode()
+			if isRed(w) {
+				w.red, parent.red = false, true
+				t.rotateLeft(parent)
+				w = parent.rightNode()
+			}
+			if !isRed(w.leftNode()) && !isRed(w.rightNode()) {
+				w.red = true
+				x, parent = parent, parent.parentNode()
+				continue
+			}
+			if !isRed(w.rightNode()) {
+				w.leftNode().red, w.red = false, true
+				t.rotateRight(w)
+				w = parent.rightNode()
+			}
+			w.red, parent.red = parent.red, false
+			w.rightNode().red = false
+			t.rotateLeft(parent)
+			x = t.root
+		} else {
+			w := parent.leftNode()
+			if isRed(w) {
+				w.red, parent.red = false, true
+				t.rotateRight(parent)
+				w = parent.leftNode()
+			}
+			if !isRed(w.leftNode()) && !isRed(w.rightNode()) {
+				w.red = true
+				x, parent = parent, parent.parentNode()
+				continue
+			}
+			if !isRed(w.leftNode()) {
+				w.rightNode().red, w.red = false, true
+				t.rotateLeft(w)
+				w = parent.leftNode()
+			}
+			w.red, parent.red = parent.red, false
+			w.leftNode().red = false
+			t.rotateRight(parent)
+			x = t.root
+		}
+	}
+	if x != nil {
+		x.red = false
+	}
+}
+
+// transplant 用 v 顶替 u 在其父亲下的位置(v 可为 nil);u 为根时 v 成为新根。
+// v 为 nil 时必须写入 nil 接口,不能写入 (*rbNode[T])(nil),否则 GetLeft() != nil 判断失效。
+func (t *RBTree[T]) transplant(u, v *rbNode[T]) {
+	var child bst.BSTNodeInterface[T]
+	if v != nil {
+		child = v
+	}
+
+	up := u.parentNode()
+	if up == nil {
+		t.root = v
+	} else if bst.IsLeftChild[T](up, u) {
+		up.SetLeft(child)
+	} else {
+		up.SetRight(child)
+	}
+
+	if v != nil {
+		v.SetParent(u.GetParent())
+	}
+}
+
+// rotateLeft 调用 bst.RotateLeft(已同步 parent 与 size),必要时更新根指针。
+func (t *RBTree[T]) rotateLeft(p *rbNode[T]) {
+	if r := bst.RotateLeft[T](p); r.GetParent() == nil {
+		t.root = r.(*rbNode[T])
+	}
+}
+
+// rotateRight 是 rotateLeft 的镜像。
+func (t *RBTree[T]) rotateRight(p *rbNode[T]) {
+	if l := bst.RotateRight[T](p); l.GetParent() == nil {
+		t.root = l.(*rbNode[T])
+	}
+}
@@ -0,0 +1,61 @@
+package rbtree
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// collect 按升序收集全部元素
+func collect(t *RBTree[int]) []int {
+	out := make([]int, 0, t.Size())
+	t.InOrderTraverse(func(item int) {
+		out = append(out, item)
+	})
+	return out
+}
+
+// TestRBTreeAgainstModel 随机插入/删除(允许重复元素),每一步后校验红黑性质并与有序切片模型比对
+func TestRBTreeAgainstModel(t *testing.T) {
+	tr := NewRBTree(cmp.Compare[int])
+	var ref []int
+	for step := range 3000 {
+		v := rand.IntN(200)
+		i, found := slices.BinarySearch(ref, v)
+		if rand.IntN(3) == 0 {
+			if got := tr.Delete(v); got != found {
+				t.Fatalf("step %d: Delete(%d) = %v, want %v", step, v, got, found)
+			}
+			if found {
+				ref = slices.Delete(ref, i, i+1)
+			}
+		} else {
+			tr.Insert(v)
+			ref = slices.Insert(ref, i, v)
+		}
+
+		if err := tr.CheckInvariants(); err != nil {
+			t.Fatalf("step %d: %v", step, err)
+		}
+		if tr.Size() != len(ref) {
+			t.Fatalf("step %d: Size = %d, want %d", step, tr.Size(), len(ref))
+		}
+	}
+	if got := collect(tr); !slices.Equal(got, ref) {
+		t.Fatalf("InOrderTraverse = %v, want %v", got, ref)
+	}
+
+	// 全部删空
+	for _, v := range slices.Clone(ref) {
+		if !tr.Delete(v) {
+			t.Fatalf("Delete(%d) = false", v)
+		}
+		if err := tr.CheckInvariants(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !tr.IsEmpty() {
+		t.Fatalf("tree not empty after deleting all, Size = %d", tr.Size())
+	}
+}
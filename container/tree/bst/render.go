@@ -0,0 +1,70 @@
+package bst
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/leoheung/go-patterns/container/render"
+)
+
+// NodeDecorator 是节点可选实现的接口,用于在 DOT / Mermaid 输出中补充实现特有的信息。
+//
+// ToGraph 为每个节点生成默认的 render.Node(Label 为 fmt.Sprint(val))后,
+// 若节点实现了 NodeDecorator 就调用 Decorate,由实现追加标签行(如 treap 的 priority、
+// AVL 的 height)或设置填充色(如红黑树的颜色)。
+//
+// 约束:Decorate 只能修改传入的 render.Node,不得修改 ID,也不得修改树结构。
+type NodeDecorator interface {
+	Decorate(n *render.Node)
+}
+
+// ToGraph 把以 p 为根的子树转换为 render.Graph,节点按先序编号为 n0, n1, ...。
+// 单孩子节点会补一个不可见的占位兄弟,使左右位置在渲染结果中保持正确。
+func ToGraph[T any](p BSTNodeInterface[T]) *render.Graph {
+	g := render.NewGraph("BST", render.TopDown)
+	if nodeIsNil(p) {
+		return g
+	}
+	next := 0
+	graph_rec(g, p, &next)
+	return g
+}
+
+// DrawDOT 以 Graphviz DOT 格式输出以 p 为根的子树。
+func DrawDOT[T any](p BSTNodeInterface[T], out io.Writer) error {
+	return ToGraph(p).WriteDOT(out)
+}
+
+// DrawMermaid 以 Mermaid flowchart 格式输出以 p 为根的子树。
+func DrawMermaid[T any](p BSTNodeInterface[T], out io.Writer) error {
+	return ToGraph(p).WriteMermaid(out)
+}
+
+// graph_rec 先序添加节点 n 及其子树;n 的 ID 取自 *next。
+func graph_rec[T any](g *render.Graph, n BSTNodeInterface[T], next *int) {
+	id := fmt.Sprintf("n%d", *next)
+	*next++
+
+	rn := render.Node{ID: id, Label: fmt.Sprint(n.GetVal())}
+	if d, ok := n.(NodeDecorator); ok {
+		d.Decorate(&rn)
+		rn.ID = id
+	}
+	g.AddNode(rn)
+
+	l, r := n.GetLeft(), n.GetRight()
+	if nodeIsNil(l) && nodeIsNil(r) {
+		return
+	}
+	for _, c := range []BSTNodeInterface[T]{l, r} {
+		if nodeIsNil(c) {
+			ph := fmt.Sprintf("%s_nil%d", id, *next)
+			*next++
+			g.AddNode(render.Node{ID: ph, Invisible: true})
+			g.AddEdge(render.Edge{From: id, To: ph, Invisible: true})
+			continue
+		}
+		g.AddEdge(render.Edge{From: id, To: fmt.Sprintf("n%d", *next)})
+		graph_rec(g, c, next)
+	}
+}
@@ -0,0 +1,37 @@
+package splay
+
+import (
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+// splayNode 不需要额外的平衡字段:splay tree 只依靠访问时的旋转摊还平衡。
+type splayNode[T any] struct {
+	*bst.Node[T] // 嵌入指针,所有 BSTNodeInterface method 会被 method promotion 提升到 *splayNode[T]
+}
+
+func new_splay_node[T any](val T, cmp func(a, b T) int) *splayNode[T] {
+	return &splayNode[T]{
+		bst.NewNode(val, cmp),
+	}
+}
+
+func (n *splayNode[T]) leftNode() *splayNode[T] {
+	if l := n.GetLeft(); l != nil {
+		return l.(*splayNode[T])
+	}
+	return nil
+}
+
+func (n *splayNode[T]) rightNode() *splayNode[T] {
+	if r := n.GetRight(); r != nil {
+		return r.(*splayNode[T])
+	}
+	return nil
+}
+
+func (n *splayNode[T]) parentNode() *splayNode[T] {
+	if pp := n.GetParent(); pp != nil {
+		return pp.(*splayNode[T])
+	}
+	return nil
+}
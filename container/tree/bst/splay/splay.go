@@ -0,0 +1,375 @@
+package splay
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+// SplayTree 伸展树:每次 Get / Insert / Delete / Predecessor / Successor 都把
+// 访问到的节点伸展至根,热点元素的访问摊还 O(1),任意操作序列摊还 O(log n)。
+// 因为这些「查询」会改变树结构,SplayTree 的读操作同样不能并发执行。
+type SplayTree[T any] struct {
+	root *splayNode[T]
+	cmp  func(a, b T) int
+}
+
+var _ bst.SelfBalancingBST[int] = new(SplayTree[int])
+
+func NewSplayTree[T any](cmp func(a, b T) int) *SplayTree[T] {
+	return &SplayTree[T]{
+		root: nil,
+		cmp:  cmp,
+	}
+}
+
+// Clear implements [bst.SelfBalancingBST].
+func (t *SplayTree[T]) Clear() {
+	t.root = nil
+}
+
+// Delete 把目标节点伸展至根后摘除,再把左右子树 join 回去。
+func (t *SplayTree[T]) Delete(item T) bool {
+	hit, last := t.find(item)
+	if hit == nil {
+		t.splay(last)
+		return false
+	}
+	t.deleteNode(hit)
+	return true
+}
+
+// Get 命中时把命中节点伸展至根;未命中时伸展最后访问的节点。
+func (t *SplayTree[T]) Get(item T) (T, bool) {
+	var zero T
+	hit, last := t.find(item)
+	if hit == nil {
+		t.splay(last)
+		return zero, false
+	}
+	t.splay(hit)
+	return hit.GetVal(), true
+}
+
+// InOrderTraverse 中序遍历，按 cmp 升序对每个元素调用 fn。
+func (t *SplayTree[T]) InOrderTraverse(fn func(T)) {
+	bst.InOrderTraverse(t.root, fn)
+}
+
+// PreorderTraverse 前序遍历。
+func (t *SplayTree[T]) PreorderTraverse(fn func(T)) {
+	bst.PreorderTraverse(t.root, fn)
+}
+
+// PostorderTraverse 后序遍历。
+func (t *SplayTree[T]) PostorderTraverse(fn func(T)) {
+	bst.PostorderTraverse(t.root, fn)
+}
+
+// IsEmpty implements [bst.SelfBalancingBST].
+func (t *SplayTree[T]) IsEmpty() bool {
+	return t.root == nil
+}
+
+// IsLessThan implements [bst.SelfBalancingBST].
+func (t *SplayTree[T]) IsLessThan() func(a T, b T) int {
+	return t.cmp
+}
+
+// Max 返回树中最大元素；空树返回 (zero, false)。
+func (t *SplayTree[T]) Max() (T, bool) {
+	return bst.Max(t.root)
+}
+
+// Min 返回树中最小元素；空树返回 (zero, false)。
+func (t *SplayTree[T]) Min() (T, bool) {
+	return bst.Min(t.root)
+}
+
+// Predecessor 返回严格小于 item 的最大元素，并把该节点伸展至根；
+// 不存在时返回 (zero, false),并伸展最后访问的节点。
+func (t *SplayTree[T]) Predecessor(item T) (T, bool) {
+	var zero T
+	var pred, last *splayNode[T]
+	for cur := t.root; cur != nil; {
+		last = cur
+		if t.cmp(cur.GetVal(), item) < 0 {
+			pred = cur
+			cur = cur.rightNode()
+		} else {
+			cur = cur.leftNode()
+		}
+	}
+	if pred == nil {
+		t.splay(last)
+		return zero, false
+	}
+	t.splay(pred)
+	return pred.GetVal(), true
+}
+
+// Successor 返回严格大于 item 的最小元素，并把该节点伸展至根；
+// 不存在时返回 (zero, false),并伸展最后访问的节点。
+func (t *SplayTree[T]) Successor(item T) (T, bool) {
+	var zero T
+	var succ, last *splayNode[T]
+	for cur := t.root; cur != nil; {
+		last = cur
+		if t.cmp(cur.GetVal(), item) > 0 {
+			succ = cur
+			cur = cur.leftNode()
+		} else {
+			cur = cur.rightNode()
+		}
+	}
+	if succ == nil {
+		t.splay(last)
+		return zero, false
+	}
+	t.splay(succ)
+	return succ.GetVal(), true
+}
+
+// Insert 按 BST 规则挂接新节点（相等元素走左），沿父链刷新 size，再把新节点伸展至根。
+func (t *SplayTree[T]) Insert(item T) {
+	n := new_splay_node(item, t.cmp)
+	if t.root == nil {
+		t.root = n
+		return
+	}
+	insert_rec(t.root, n)
+	t.splay(n)
+}
+
+// Update 定位与 item 相等的节点，调用 callback 修改内容，随后按需重排。
+// 仅当修改后不再满足 BST 有序不变量时才删除并重新插入。
+func (t *SplayTree[T]) Update(item T, callback func(item T)) {
+	hit, last := t.find(item)
+	if hit == nil {
+		t.splay(last)
+		return // 不存在则不回调、不新增
+	}
+	callback(hit.GetVal())
+
+	if bst.IsOrderedNode(hit) {
+		t.splay(hit)
+		return // 仍有序，免重排
+	}
+	// 乱序：按节点引用删除 + 重新插入。
+	// 不能用 Delete(item) 重新定位：callback 已改变排序键，按新键搜索无法命中该节点。
+	// 伸展只依赖结构旋转、不做比较，对乱序节点同样安全。
+	val := hit.GetVal()
+	t.deleteNode(hit)
+	t.Insert(val)
+}
+
+// RangeVisit 闭区间 [low, high] 升序遍历；调用方需保证 low ≤ high。
+func (t *SplayTree[T]) RangeVisit(low T, high T, callback func(T)) {
+	bst.RangeVisit(t.root, low, high, callback)
+}
+
+// Rank 返回严格小于 item 的元素个数（0-based）。
+func (t *SplayTree[T]) Rank(item T) int {
+	return bst.Rank(t.root, item)
+}
+
+// Select 返回第 rank 小（0-based）元素；越界返回 (zero, false)。
+func (t *SplayTree[T]) Select(rank int) (T, bool) {
+	return bst.Select(t.root, rank)
+}
+
+// Size implements [bst.SelfBalancingBST].
+func (t *SplayTree[T]) Size() int {
+	if t.root == nil {
+		return 0
+	}
+	return t.root.GetSize()
+}
+
+// DrawTree 把整棵树以带缩进的树形文本输出到 out（仅打印节点值，用于观察结构）。
+func (t *SplayTree[T]) DrawTree(out io.Writer) {
+	bst.DrawTree(t.root, out)
+}
+
+// DrawDOT implements [bst.SelfBalancingBST].
+func (t *SplayTree[T]) DrawDOT(out io.Writer) error {
+	return bst.DrawDOT(t.root, out)
+}
+
+// DrawMermaid implements [bst.SelfBalancingBST].
+func (t *SplayTree[T]) DrawMermaid(out io.Writer) error {
+	return bst.DrawMermaid(t.root, out)
+}
+
+// Split 按 item 把树一分为二:t 保留全部 <= item 的元素,返回的新树持有全部 > item 的元素。
+// 做法是把最后一个 <= item 的节点伸展至根,再切下它的右子树;摊还 O(log n)。
+// 新树与 t 共用同一个比较器。
+func (t *SplayTree[T]) Split(item T) *SplayTree[T] {
+	right := NewSplayTree(t.cmp)
+
+	var le, last *splayNode[T]
+	for cur := t.root; cur != nil; {
+		last = cur
+		if t.cmp(cur.GetVal(), item) <= 0 {
+			le = cur
+			cur = cur.rightNode()
+		} else {
+			cur = cur.leftNode()
+		}
+	}
+
+	if le == nil {
+		// 全部元素都 > item
+		t.splay(last)
+		right.root, t.root = t.root, nil
+		return right
+	}
+
+	t.splay(le)
+	if r := le.rightNode(); r != nil {
+		le.SetRight(nil)
+		r.SetParent(nil)
+		bst.UpdateSize[T](le)
+		right.root = r
+	}
+	return right
+}
+
+// Join 把 other 的全部元素并入 t,other 随后变为空树;摊还 O(log n)。
+// 要求 t 的所有元素 <= other 的所有元素,且两棵树使用同一个比较器;
+// 元素区间重叠时返回错误,两棵树保持不变。
+func (t *SplayTree[T]) Join(other *SplayTree[T]) error {
+	if other == nil || other == t || other.root == nil {
+		return nil
+	}
+	if t.root == nil {
+		t.root, other.root = other.root, nil
+		return nil
+	}
+
+	tMax, _ := t.Max()
+	otherMin, _ := other.Min()
+	if t.cmp(tMax, otherMin) > 0 {
+		return fmt.Errorf("failed to join: max %v of the receiver is greater than min %v of other", tMax, otherMin)
+	}
+
+	t.root = join(t.root, other.root)
+	other.root = nil
+	return nil
+}
+
+// find 查找与 item 相等的节点;未命中时 hit 为 nil,last 为最后访问的节点(空树为 nil)。
+func (t *SplayTree[T]) find(item T) (hit, last *splayNode[T]) {
+	for cur := t.root; cur != nil; {
+		last = cur
+		c := t.cmp(cur.GetVal(), item)
+		switch {
+		case c == 0:
+			return cur, last
+		case c < 0:
+			cur = cur.rightNode()
+		default:
+			cur = cur.leftNode()
+		}
+	}
+	return nil, last
+}
+
+// deleteNode 把 z 伸展至根后摘除,再 join 左右子树。
+func (t *SplayTree[T]) deleteNode(z *splayNode[T]) {
+	t.splay(z)
+
+	l, r := z.leftNode(), z.rightNode()
+	if l != nil {
+		l.SetParent(nil)
+	}
+	if r != nil {
+		r.SetParent(nil)
+	}
+	t.root = join(l, r)
+}
+
+// join 合并两棵独立子树(根的 parent 均为 nil),要求 l 的所有元素 <= r 的所有元素。
+// 把 l 的最大节点伸展到 l 的根(此时它没有右孩子),再把 r 挂为其右孩子。
+func join[T any](l, r *splayNode[T]) *splayNode[T] {
+	if l == nil {
+		return r
+	}
+	if r == nil {
+		return l
+	}
+
+	m := l
+	for m.rightNode() != nil {
+		m = m.rightNode()
+	}
+	splay_to_root(m)
+
+	m.SetRight(r)
+	r.SetParent(m)
+	bst.UpdateSize[T](m)
+	return m
+}
+
+// splay 把 x 伸展至整棵树的根;x 为 nil 时无副作用。
+func (t *SplayTree[T]) splay(x *splayNode[T]) {
+	if x == nil {
+		return
+	}
+	splay_to_root(x)
+	t.root = x
+}
+
+// splay_to_root 通过 zig / zig-zig / zig-zag 旋转把 x 提升为其所在树的根。
+// bst.RotateLeft / RotateRight 会同步 parent 与局部 size;旋转不改变子树总 size,
+// 因此祖先的 size 不需要刷新。
+func splay_to_root[T any](x *splayNode[T]) {
+	for p := x.parentNode(); p != nil; p = x.parentNode() {
+		g := p.parentNode()
+		switch {
+		case g == nil:
+			// zig
+			rotate_up(x)
+		case bst.IsLeftChild[T](p, x) == bst.IsLeftChild[T](g, p):
+			// zig-zig:先转祖父,再转父亲
+			rotate_up(p)
+			rotate_up(x)
+		default:
+			// zig-zag:连续两次把 x 向上转
+			rotate_up(x)
+			rotate_up(x)
+		}
+	}
+}
+
+// rotate_up 以 x 的父亲为轴旋转,使 x 上升一层。
+func rotate_up[T any](x *splayNode[T]) {
+	p := x.parentNode()
+	if bst.IsLeftChild[T](p, x) {
+		bst.RotateRight[T](p)
+	} else {
+		bst.RotateLeft[T](p)
+	}
+}
+
+// insert_rec 把新节点 nn 挂到以 cur 为根的子树中；相等元素走左，允许重复共存。
+func insert_rec[T any](cur, nn *splayNode[T]) {
+	if cur.CompareFn()(cur.GetVal(), nn.GetVal()) >= 0 {
+		if cur.leftNode() == nil {
+			cur.SetLeft(nn)
+			nn.SetParent(cur)
+			bst.RefreshSizeUp[T](cur)
+		} else {
+			insert_rec(cur.leftNode(), nn)
+		}
+	} else {
+		if cur.rightNode() == nil {
+			cur.SetRight(nn)
+			nn.SetParent(cur)
+			bst.RefreshSizeUp[T](cur)
+		} else {
+			insert_rec(cur.rightNode(), nn)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package treap
+
+import (
+	"fmt"
+
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+// 本文件提供基于 split / merge 的整树操作。
+//
+// 与 Insert / Delete 的「旋转」路线不同,这里的算法直接按 priority 递归重组子树:
+//   - split 把一棵 treap 按谓词切成左右两棵,O(log n)
+//   - merge 把两棵「左 <= 右」的 treap 按 priority 拼接,O(log n)
+//
+// Union / Intersection / Difference 采用 Blelloch & Reid-Miller 的并行友好算法:
+// 每一步取两棵树中 priority 更高的根作为枢轴,把另一棵树按枢轴切开后递归。
+// 较小树规模为 m、较大树为 n 时期望 O(m log(n/m + 1))。
+//
+// 集合运算以「相等类」(cmp == 0)为单位判定成员关系:
+//   - Union:        t 有的类保留 t 的全部副本;只在 other 出现的类保留 other 的全部副本
+//   - Intersection: 两边都有的类,保留 t 的全部副本
+//   - Difference:   other 中没有的类,保留 t 的全部副本
+//
+// 这些操作都会「消费」参与运算的节点:结果写回 t,other 被清空;
+// 两棵树必须使用同一个比较器(同一个函数值),否则结果未定义。
+
+// BuildFromSorted 用已按 cmp 升序(允许相等)排列的切片在 O(n) 内建树。
+// 为每个元素随机分配 priority,再用单调栈构造笛卡尔树(中序 = 切片顺序,堆序 = priority);
+// 切片未排序时返回错误。
+func BuildFromSorted[T any](sorted []T, cmp func(a, b T) int) (*Treap[T], error) {
+	t := NewTreap(cmp)
+	if len(sorted) == 0 {
+		return t, nil
+	}
+
+	// stack 保存当前最右链,priority 自底向上递减
+	stack := make([]*treapNode[T], 0)
+	for i, v := range sorted {
+		if i > 0 && cmp(sorted[i-1], v) > 0 {
+			return nil, fmt.Errorf("failed to build treap: input is not sorted at index %d", i)
+		}
+
+		n := new_treap_node(v, cmp)
+		var last *treapNode[T]
+		for len(stack) > 0 && stack[len(stack)-1].priority < n.priority {
+			last = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		}
+		set_left(n, last)
+		if len(stack) > 0 {
+			set_right(stack[len(stack)-1], n)
+		}
+		stack = append(stack, n)
+	}
+
+	t.root = stack[0]
+	t.root.SetParent(nil)
+	refresh_sizes(t.root)
+	return t, nil
+}
+
+// Split 按 item 把树一分为二:t 保留全部 <= item 的元素,返回的新树持有全部 > item 的元素。
+// 期望 O(log n);新树与 t 共用同一个比较器。
+func (t *Treap[T]) Split(item T) *Treap[T] {
+	l, r := split(t.root, func(v T) bool { return t.cmp(v, item) <= 0 })
+	t.root = detach(l)
+
+	right := NewTreap(t.cmp)
+	right.root = detach(r)
+	return right
+}
+
+// Merge 把 other 的全部元素并入 t,other 随后变为空树;期望 O(log n)。
+// 要求 t 的所有元素 <= other 的所有元素,且两棵树使用同一个比较器;
+// 元素区间重叠时返回错误,两棵树保持不变。
+func (t *Treap[T]) Merge(other *Treap[T]) error {
+	if other == nil || other == t || other.root == nil {
+		return nil
+	}
+	if t.root != nil {
+		tMax, _ := t.Max()
+		otherMin, _ := other.Min()
+		if t.cmp(tMax, otherMin) > 0 {
+			return fmt.Errorf("failed to merge: max %v of the receiver is greater than min %v of other", tMax, otherMin)
+		}
+	}
+
+	t.root = detach(merge(t.root, other.root))
+	other.root = nil
+	return nil
+}
+
+// DeleteRange 删除闭区间 [low, high] 内的全部元素,返回删除个数;期望 O(log n)。
+// 调用方需保证 low ≤ high。
+func (t *Treap[T]) DeleteRange(low, high T) int {
+	lt, ge := split(t.root, func(v T) bool { return t.cmp(v, low) < 0 })
+	mid, gt := split(ge, func(v T) bool { return t.cmp(v, high) <= 0 })
+
+	removed := 0
+	if mid != nil {
+		removed = mid.GetSize()
+	}
+	t.root = detach(merge(lt, gt))
+	return removed
+}
+
+// Union 把 other 并入 t(集合并,见文件头的相等类约定),other 随后变为空树。
+func (t *Treap[T]) Union(other *Treap[T]) {
+	if other == nil || other == t {
+		return
+	}
+	t.root = detach(union(t.root, other.root))
+	other.root = nil
+}
+
+// Intersection 让 t 只保留在 other 中也存在的元素(集合交),other 随后变为空树。
+func (t *Treap[T]) Intersection(other *Treap[T]) {
+	if other == nil || other == t {
+		return
+	}
+	t.root = detach(intersection(t.root, other.root))
+	other.root = nil
+}
+
+// Difference 从 t 中移除在 other 中存在的元素(集合差),other 随后变为空树。
+func (t *Treap[T]) Difference(other *Treap[T]) {
+	if other == nil {
+		return
+	}
+	if other == t {
+		t.root = nil
+		return
+	}
+	t.root = detach(difference(t.root, other.root))
+	other.root = nil
+}
+
+// split 把以 n 为根的子树切成两棵:toLeft(v) 为 true 的元素进左树,其余进右树。
+// toLeft 必须对中序序列单调(一旦为 false,之后的元素都为 false)。
+// 返回的两个子树根的 parent 未定义,由调用方挂接或 detach。
+func split[T any](n *treapNode[T], toLeft func(T) bool) (*treapNode[T], *treapNode[T]) {
+	if n == nil {
+		return nil, nil
+	}
+	if toLeft(n.GetVal()) {
+		l, r := split(n.rightNode(), toLeft)
+		set_right(n, l)
+		bst.UpdateSize[T](n)
+		return n, r
+	}
+	l, r := split(n.leftNode(), toLeft)
+	set_left(n, r)
+	bst.UpdateSize[T](n)
+	return l, n
+}
+
+// split3 以 v 为界把子树切成 < v、== v、> v 三棵。
+func split3[T any](n *treapNode[T], v T) (lt, eq, gt *treapNode[T]) {
+	if n == nil {
+		return nil, nil, nil
+	}
+	cmp := n.CompareFn()
+	lt, ge := split(n, func(x T) bool { return cmp(x, v) < 0 })
+	eq, gt = split(ge, func(x T) bool { return cmp(x, v) <= 0 })
+	return lt, eq, gt
+}
+
+// merge 拼接 a、b 两棵子树,要求 a 的所有元素 <= b 的所有元素;priority 高者为根。
+func merge[T any](a, b *treapNode[T]) *treapNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority >= b.priority {
+		set_right(a, merge(a.rightNode(), b))
+		bst.UpdateSize[T](a)
+		return a
+	}
+	set_left(b, merge(a, b.leftNode()))
+	bst.UpdateSize[T](b)
+	return b
+}
+
+// merge3 依次拼接 a、b、c 三棵有序子树。
+func merge3[T any](a, b, c *treapNode[T]) *treapNode[T] {
+	return merge(merge(a, b), c)
+}
+
+// attach 以 root 为根挂接左右子树并刷新 size;调用方保证 root 的 priority 不低于两棵子树。
+func attach[T any](root, l, r *treapNode[T]) *treapNode[T] {
+	set_left(root, l)
+	set_right(root, r)
+	bst.UpdateSize[T](root)
+	return root
+}
+
+// union 见 Treap.Union;a 来自 t,b 来自 other。
+func union[T any](a, b *treapNode[T]) *treapNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	if a.priority >= b.priority {
+		// 枢轴来自 t:other 中的同类元素全部丢弃
+		v := a.GetVal()
+		bl, _, bg := split3(b, v)
+		al, ar := a.leftNode(), a.rightNode()
+		return attach(a, union(al, bl), union(ar, bg))
+	}
+
+	// 枢轴来自 other
+	v := b.GetVal()
+	al, ae, ag := split3(a, v)
+	bl, br := b.leftNode(), b.rightNode()
+	if ae == nil {
+		return attach(b, union(al, bl), union(ag, br))
+	}
+
+	// t 中已有同类元素:丢弃枢轴,并剥离 other 子树里与枢轴相等的副本
+	cmp := b.CompareFn()
+	bl, _ = split(bl, func(x T) bool { return cmp(x, v) < 0 })
+	_, br = split(br, func(x T) bool { return cmp(x, v) <= 0 })
+	return merge3(union(al, bl), ae, union(ag, br))
+}
+
+// intersection 见 Treap.Intersection;a 来自 t,b 来自 other。
+func intersection[T any](a, b *treapNode[T]) *treapNode[T] {
+	if a == nil || b == nil {
+		return nil
+	}
+
+	if a.priority >= b.priority {
+		// 枢轴来自 t
+		v := a.GetVal()
+		bl, be, bg := split3(b, v)
+		al, ar := a.leftNode(), a.rightNode()
+		if be == nil {
+			// other 中没有同类元素:枢轴及 t 子树中的同类副本都会在递归中被丢弃
+			return merge(intersection(al, bl), intersection(ar, bg))
+		}
+		// other 中有同类元素:保留枢轴,并把 t 子树里的同类副本剥离出来整体保留
+		cmp := a.CompareFn()
+		al, ale := split(al, func(x T) bool { return cmp(x, v) < 0 })
+		are, ag := split(ar, func(x T) bool { return cmp(x, v) <= 0 })
+		return attach(a, merge(intersection(al, bl), ale), merge(are, intersection(ag, bg)))
+	}
+
+	// 枢轴来自 other:t 中的同类元素整体保留
+	v := b.GetVal()
+	al, ae, ag := split3(a, v)
+	return merge3(intersection(al, b.leftNode()), ae, intersection(ag, b.rightNode()))
+}
+
+// difference 见 Treap.Difference;a 来自 t,b 来自 other。
+func difference[T any](a, b *treapNode[T]) *treapNode[T] {
+	if a == nil {
+		return nil
+	}
+	if b == nil {
+		return a
+	}
+
+	if a.priority >= b.priority {
+		// 枢轴来自 t
+		v := a.GetVal()
+		bl, be, bg := split3(b, v)
+		al, ar := a.leftNode(), a.rightNode()
+		if be == nil {
+			return attach(a, difference(al, bl), difference(ar, bg))
+		}
+		// other 中有同类元素:丢弃枢轴以及 t 子树里的全部同类副本
+		cmp := a.CompareFn()
+		al, _ = split(al, func(x T) bool { return cmp(x, v) < 0 })
+		_, ag := split(ar, func(x T) bool { return cmp(x, v) <= 0 })
+		return merge(difference(al, bl), difference(ag, bg))
+	}
+
+	// 枢轴来自 other:t 中的同类元素全部丢弃
+	v := b.GetVal()
+	al, _, ag := split3(a, v)
+	return merge(difference(al, b.leftNode()), difference(ag, b.rightNode()))
+}
+
+// set_left 把 c 挂为 n 的左孩子;c 为 nil 时写入 nil 接口而不是 (*treapNode[T])(nil)。
+func set_left[T any](n, c *treapNode[T]) {
+	if c == nil {
+		n.SetLeft(nil)
+		return
+	}
+	n.SetLeft(c)
+	c.SetParent(n)
+}
+
+// set_right 是 set_left 的镜像。
+func set_right[T any](n, c *treapNode[T]) {
+	if c == nil {
+		n.SetRight(nil)
+		return
+	}
+	n.SetRight(c)
+	c.SetParent(n)
+}
+
+// detach 把 n 作为整棵树的根(parent 置空)并返回。
+func detach[T any](n *treapNode[T]) *treapNode[T] {
+	if n != nil {
+		n.SetParent(nil)
+	}
+	return n
+}
+
+// refresh_sizes 后序遍历自底向上重算整棵子树的 size。
+func refresh_sizes[T any](n *treapNode[T]) {
+	if n == nil {
+		return
+	}
+	refresh_sizes(n.leftNode())
+	refresh_sizes(n.rightNode())
+	bst.UpdateSize[T](n)
+}
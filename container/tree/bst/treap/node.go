@@ -1,8 +1,10 @@
 package treap
 
 import (
+	"fmt"
 	"math/rand/v2"
 
+	"github.com/leoheung/go-patterns/container/render"
 	"github.com/leoheung/go-patterns/container/tree/bst"
 )
 
@@ -38,3 +40,10 @@ func (n *treapNode[T]) parentNode() *treapNode[T] {
 	}
 	return nil
 }
+
+var _ bst.NodeDecorator = new(treapNode[int])
+
+// Decorate implements [bst.NodeDecorator]:标签追加堆优先级。
+func (n *treapNode[T]) Decorate(rn *render.Node) {
+	rn.Label += fmt.Sprintf("\np=%d", n.priority)
+}
@@ -271,3 +271,13 @@ func insert_rec[T any](cur, nn *treapNode[T]) {
 func (t *Treap[T]) DrawTree(out io.Writer) {
 	bst.DrawTree(t.root, out)
 }
+
+// DrawDOT implements [bst.SelfBalancingBST].
+func (t *Treap[T]) DrawDOT(out io.Writer) error {
+	return bst.DrawDOT(t.root, out)
+}
+
+// DrawMermaid implements [bst.SelfBalancingBST].
+func (t *Treap[T]) DrawMermaid(out io.Writer) error {
+	return bst.DrawMermaid(t.root, out)
+}
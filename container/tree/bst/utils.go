@@ -40,6 +40,9 @@ func IsLeftChild[T any](parent, child BSTNodeInterface[T]) bool {
 //
 //	旋转路径上「n 的祖先节点」的 size 必须由调用方显式刷新
 //	(因为 rotate 函数本身只更新旋转局部的 size,见各 rotate 文档)。
+//
+// 若 n 实现了 Augmentable,刷新 size 之后会紧接着调用 n.Augment(),
+// 因此自定义的子树聚合值与 size 在完全相同的时机被维护。
 func UpdateSize[T any](n BSTNodeInterface[T]) {
 	if n == nil {
 		return
@@ -52,6 +55,10 @@ func UpdateSize[T any](n BSTNodeInterface[T]) {
 		sz += r.GetSize()
 	}
 	n.SetSize(sz)
+
+	if a, ok := n.(Augmentable); ok {
+		a.Augment()
+	}
 }
 
 // RotateLeft 对以 p 为根的子树做左旋。
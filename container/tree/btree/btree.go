@@ -0,0 +1,227 @@
+package btree
+
+import (
+	"iter"
+)
+
+// BTree 泛型内存 B 树,元素唯一(cmp == 0 视为同一元素)。
+//
+// 与 treap / skiplist 每个元素一个节点、若干指针不同,B 树把最多 2*degree-1 个元素
+// 连续存放在同一个节点的切片里:指针开销按 degree 摊薄,查找时的比较集中在同一块内存上,
+// 对大量小 key 的有序集合更省内存、cache 命中率更高。
+//
+// Clone 以写时复制(copy-on-write)实现,O(1) 得到快照:
+// 两棵树共享全部节点,之后任一方修改时才复制沿途被改动的节点。
+//
+// BTree 不是并发安全的。Clone 出来的树与原树之间不共享可变状态,
+// 在 Clone 调用完成后可以分别交给不同 goroutine 读写。
+type BTree[T any] struct {
+	degree int
+	length int
+	root   *node[T]
+	cow    *cowContext[T]
+}
+
+// New 创建一棵最小度数为 degree 的空 B 树:
+// 除根以外每个节点保存 degree-1 ~ 2*degree-1 个元素。degree < 2 时 panic。
+//
+// degree 越大树越矮、内存越紧凑,但节点内插入 / 删除需要搬移的元素越多;
+// 小 key 场景下 16~64 通常是不错的取值。
+func New[T any](degree int, cmp func(a, b T) int) *BTree[T] {
+	if degree < 2 {
+		panic("BTree: degree must be at least 2")
+	}
+	if cmp == nil {
+		panic("BTree: cmp function cannot be nil")
+	}
+	return &BTree[T]{
+		degree: degree,
+		cow:    &cowContext[T]{cmp: cmp},
+	}
+}
+
+func (t *BTree[T]) maxItems() int { return 2*t.degree - 1 }
+func (t *BTree[T]) minItems() int { return t.degree - 1 }
+
+// Len 返回元素个数,O(1)。
+func (t *BTree[T]) Len() int { return t.length }
+
+// Clear 清空整棵树;已 Clone 出去的快照不受影响。
+func (t *BTree[T]) Clear() {
+	t.root = nil
+	t.length = 0
+}
+
+// Clone 返回当前树的快照,O(1)。
+// 原树与快照各自持有新的写时复制上下文,共享的节点对双方都是只读的,
+// 首次修改时复制,因此双方的后续修改互不可见。
+func (t *BTree[T]) Clone() *BTree[T] {
+	c1, c2 := *t.cow, *t.cow
+	out := *t
+	t.cow = &c1
+	out.cow = &c2
+	return &out
+}
+
+// ReplaceOrInsert 插入 item;已存在相等元素时用 item 替换它并返回 (旧元素, true)。O(log n)。
+func (t *BTree[T]) ReplaceOrInsert(item T) (T, bool) {
+	if t.root == nil {
+		t.root = t.cow.newNode()
+		t.root.items = append(t.root.items, item)
+		t.length++
+		var zero T
+		return zero, false
+	}
+
+	t.root = t.root.mutableFor(t.cow)
+	if len(t.root.items) >= t.maxItems() {
+		// 根满了:先分裂,树高加一
+		mid, second := t.root.split(t.maxItems() / 2)
+		old := t.root
+		t.root = t.cow.newNode()
+		t.root.items = append(t.root.items, mid)
+		t.root.children = append(t.root.children, old, second)
+	}
+
+	out, replaced := t.root.insert(item, t.maxItems())
+	if !replaced {
+		t.length++
+	}
+	return out, replaced
+}
+
+// Get 返回与 item 相等的元素。O(log n)。
+func (t *BTree[T]) Get(item T) (T, bool) {
+	for n := t.root; n != nil; {
+		i, found := n.find(item)
+		if found {
+			return n.items[i], true
+		}
+		if len(n.children) == 0 {
+			break
+		}
+		n = n.children[i]
+	}
+	var zero T
+	return zero, false
+}
+
+// Has 判断是否存在与 item 相等的元素。
+func (t *BTree[T]) Has(item T) bool {
+	_, ok := t.Get(item)
+	return ok
+}
+
+// Delete 删除与 item 相等的元素并返回它;不存在时返回 (zero, false)。O(log n)。
+func (t *BTree[T]) Delete(item T) (T, bool) {
+	return t.deleteItem(item, removeItem)
+}
+
+// DeleteMin 删除并返回最小元素;空树返回 (zero, false)。
+func (t *BTree[T]) DeleteMin() (T, bool) {
+	var zero T
+	return t.deleteItem(zero, removeMin)
+}
+
+// DeleteMax 删除并返回最大元素;空树返回 (zero, false)。
+func (t *BTree[T]) DeleteMax() (T, bool) {
+	var zero T
+	return t.deleteItem(zero, removeMax)
+}
+
+// Min 返回最小元素;空树返回 (zero, false)。
+func (t *BTree[T]) Min() (T, bool) {
+	var zero T
+	n := t.root
+	if n == nil || len(n.items) == 0 {
+		return zero, false
+	}
+	for len(n.children) > 0 {
+		n = n.children[0]
+	}
+	return n.items[0], true
+}
+
+// Max 返回最大元素;空树返回 (zero, false)。
+func (t *BTree[T]) Max() (T, bool) {
+	var zero T
+	n := t.root
+	if n == nil || len(n.items) == 0 {
+		return zero, false
+	}
+	for len(n.children) > 0 {
+		n = n.children[len(n.children)-1]
+	}
+	return n.items[len(n.items)-1], true
+}
+
+// Ascend 按升序对每个元素调用 fn,fn 返回 false 时停止。回调内禁止修改树。
+func (t *BTree[T]) Ascend(fn func(item T) bool) {
+	if t.root != nil {
+		t.root.ascend(nil, nil, fn)
+	}
+}
+
+// Descend 按降序对每个元素调用 fn,fn 返回 false 时停止。
+func (t *BTree[T]) Descend(fn func(item T) bool) {
+	if t.root != nil {
+		t.root.descend(nil, nil, fn)
+	}
+}
+
+// AscendRange 按升序遍历闭区间 [low, high] 内的元素,fn 返回 false 时停止。
+// 调用方需保证 low ≤ high;O(log n + k)。
+func (t *BTree[T]) AscendRange(low, high T, fn func(item T) bool) {
+	if t.root != nil {
+		t.root.ascend(&low, &high, fn)
+	}
+}
+
+// DescendRange 按降序遍历闭区间 [low, high] 内的元素,fn 返回 false 时停止。
+func (t *BTree[T]) DescendRange(low, high T, fn func(item T) bool) {
+	if t.root != nil {
+		t.root.descend(&low, &high, fn)
+	}
+}
+
+// RangeQuery 返回闭区间 [low, high] 内的全部元素(升序)。
+func (t *BTree[T]) RangeQuery(low, high T) []T {
+	result := make([]T, 0)
+	t.AscendRange(low, high, func(item T) bool {
+		result = append(result, item)
+		return true
+	})
+	return result
+}
+
+// All 按升序迭代全部元素。
+func (t *BTree[T]) All() iter.Seq[T] {
+	return t.Ascend
+}
+
+// Backward 按降序迭代全部元素。
+func (t *BTree[T]) Backward() iter.Seq[T] {
+	return t.Descend
+}
+
+// deleteItem 自根向下删除;沿途保证将要进入的孩子至少有 degree 个元素,
+// 因此删除不需要回溯。根变空时树高减一。
+func (t *BTree[T]) deleteItem(item T, typ toRemove) (T, bool) {
+	if t.root == nil || len(t.root.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	t.root = t.root.mutableFor(t.cow)
+	out, ok := t.root.remove(item, t.minItems(), typ)
+	if len(t.root.items) == 0 {
+		if len(t.root.children) > 0 {
+			t.root = t.root.children[0]
+		} else {
+			t.root = nil
+		}
+	}
+	if ok {
+		t.length--
+	}
+	return out, ok
+}
@@ -0,0 +1,311 @@
+package btree
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/leoheung/go-patterns/container/skiplist"
+	"github.com/leoheung/go-patterns/container/tree/bst/treap"
+)
+
+// collect 按升序收集全部元素
+func collect(t *BTree[int]) []int {
+	out := make([]int, 0, t.Len())
+	t.Ascend(func(item int) bool {
+		out = append(out, item)
+		return true
+	})
+	return out
+}
+
+// TestBTreeAgainstModel 随机插入/删除,与有序切片模型逐步比对;覆盖 degree 2 的最小情形
+func TestBTreeAgainstModel(t *testing.T) {
+	for _, degree := range []int{2, 3, 8} {
+		tr := New(degree, cmp.Compare[int])
+		var ref []int
+		for range 5000 {
+			v := rand.IntN(500)
+			i, found := slices.BinarySearch(ref, v)
+			switch op := rand.IntN(10); {
+			case op < 6:
+				if _, replaced := tr.ReplaceOrInsert(v); replaced != found {
+					t.Fatalf("degree %d: insert %d replaced=%v, want %v", degree, v, replaced, found)
+				}
+				if !found {
+					ref = slices.Insert(ref, i, v)
+				}
+			case op < 8:
+				if _, ok := tr.Delete(v); ok != found {
+					t.Fatalf("degree %d: delete %d ok=%v, want %v", degree, v, ok, found)
+				}
+				if found {
+					ref = slices.Delete(ref, i, i+1)
+				}
+			case op < 9:
+				got, ok := tr.DeleteMin()
+				if ok != (len(ref) > 0) || (ok && got != ref[0]) {
+					t.Fatalf("degree %d: DeleteMin = %d, %v", degree, got, ok)
+				}
+				if ok {
+					ref = ref[1:]
+				}
+			default:
+				got, ok := tr.DeleteMax()
+				if ok != (len(ref) > 0) || (ok && got != ref[len(ref)-1]) {
+					t.Fatalf("degree %d: DeleteMax = %d, %v", degree, got, ok)
+				}
+				if ok {
+					ref = ref[:len(ref)-1]
+				}
+			}
+
+			if tr.Len() != len(ref) {
+				t.Fatalf("degree %d: Len = %d, want %d", degree, tr.Len(), len(ref))
+			}
+			if got := collect(tr); !slices.Equal(got, ref) {
+				t.Fatalf("degree %d: Ascend = %v, want %v", degree, got, ref)
+			}
+
+			low := rand.IntN(520) - 10
+			high := low + rand.IntN(60)
+			lo, _ := slices.BinarySearch(ref, low)
+			hi, _ := slices.BinarySearch(ref, high+1)
+			if got := tr.RangeQuery(low, high); !slices.Equal(got, ref[lo:hi]) {
+				t.Fatalf("degree %d: RangeQuery(%d, %d) = %v, want %v", degree, low, high, got, ref[lo:hi])
+			}
+			var desc []int
+			tr.DescendRange(low, high, func(item int) bool {
+				desc = append(desc, item)
+				return true
+			})
+			slices.Reverse(desc)
+			if !slices.Equal(desc, ref[lo:hi]) {
+				t.Fatalf("degree %d: DescendRange(%d, %d) = %v, want %v", degree, low, high, desc, ref[lo:hi])
+			}
+		}
+	}
+}
+
+// TestBTreeCloneIsolation 快照与原树各自修改,互不可见
+func TestBTreeCloneIsolation(t *testing.T) {
+	tr := New(4, cmp.Compare[int])
+	for i := range 1000 {
+		tr.ReplaceOrInsert(i)
+	}
+	snap := tr.Clone()
+
+	for i := 0; i < 1000; i += 2 {
+		tr.Delete(i)
+	}
+	for i := 1000; i < 1500; i++ {
+		snap.ReplaceOrInsert(i)
+	}
+
+	if tr.Len() != 500 || snap.Len() != 1500 {
+		t.Fatalf("Len = %d / %d, want 500 / 1500", tr.Len(), snap.Len())
+	}
+	for i, v := range collect(tr) {
+		if v != 2*i+1 {
+			t.Fatalf("original tree corrupted at %d: %d", i, v)
+		}
+	}
+	for i, v := range collect(snap) {
+		if v != i {
+			t.Fatalf("snapshot corrupted at %d: %d", i, v)
+		}
+	}
+
+	// 快照的快照
+	snap2 := snap.Clone()
+	snap.Clear()
+	if snap2.Len() != 1500 || snap2.Has(1499) == false {
+		t.Fatalf("second-level snapshot lost data")
+	}
+}
+
+// ═══════════════════════════════════════════════════════
+// Benchmark:B 树 vs treap vs 跳表
+// ═══════════════════════════════════════════════════════
+
+const benchSize = 100_000
+
+func benchKeys() []int {
+	r := rand.New(rand.NewPCG(1, 2))
+	keys := make([]int, benchSize)
+	for i := range keys {
+		keys[i] = r.Int()
+	}
+	return keys
+}
+
+func BenchmarkInsert_BTree(b *testing.B) {
+	keys := benchKeys()
+	for b.Loop() {
+		tr := New(32, cmp.Compare[int])
+		for _, k := range keys {
+			tr.ReplaceOrInsert(k)
+		}
+	}
+}
+
+func BenchmarkInsert_Treap(b *testing.B) {
+	keys := benchKeys()
+	for b.Loop() {
+		tr := treap.NewTreap(cmp.Compare[int])
+		for _, k := range keys {
+			tr.Insert(k)
+		}
+	}
+}
+
+func BenchmarkInsert_SkipList(b *testing.B) {
+	keys := benchKeys()
+	for b.Loop() {
+		sl := skiplist.New(func(a, b int) bool { return a < b }, false)
+		for _, k := range keys {
+			sl.Insert(k)
+		}
+	}
+}
+
+func BenchmarkGet_BTree(b *testing.B) {
+	keys := benchKeys()
+	tr := New(32, cmp.Compare[int])
+	for _, k := range keys {
+		tr.ReplaceOrInsert(k)
+	}
+	i := 0
+	for b.Loop() {
+		tr.Get(keys[i%benchSize])
+		i++
+	}
+}
+
+func BenchmarkGet_Treap(b *testing.B) {
+	keys := benchKeys()
+	tr := treap.NewTreap(cmp.Compare[int])
+	for _, k := range keys {
+		tr.Insert(k)
+	}
+	i := 0
+	for b.Loop() {
+		tr.Get(keys[i%benchSize])
+		i++
+	}
+}
+
+func BenchmarkGet_SkipList(b *testing.B) {
+	keys := benchKeys()
+	sl := skiplist.New(func(a, b int) bool { return a < b }, false)
+	for _, k := range keys {
+		sl.Insert(k)
+	}
+	i := 0
+	for b.Loop() {
+		sl.Search(keys[i%benchSize])
+		i++
+	}
+}
+
+func BenchmarkAscend_BTree(b *testing.B) {
+	tr := New(32, cmp.Compare[int])
+	for _, k := range benchKeys() {
+		tr.ReplaceOrInsert(k)
+	}
+	for b.Loop() {
+		sum := 0
+		tr.Ascend(func(item int) bool {
+			sum += item
+			return true
+		})
+	}
+}
+
+func BenchmarkAscend_Treap(b *testing.B) {
+	tr := treap.NewTreap(cmp.Compare[int])
+	for _, k := range benchKeys() {
+		tr.Insert(k)
+	}
+	for b.Loop() {
+		sum := 0
+		tr.InOrderTraverse(func(item int) {
+			sum += item
+		})
+	}
+}
+
+func BenchmarkAscend_SkipList(b *testing.B) {
+	sl := skiplist.New(func(a, b int) bool { return a < b }, false)
+	for _, k := range benchKeys() {
+		sl.Insert(k)
+	}
+	for b.Loop() {
+		sum := 0
+		for _, item := range sl.GetAll() {
+			sum += item
+		}
+	}
+}
+
+func BenchmarkDeleteMin_BTree(b *testing.B) {
+	keys := benchKeys()
+	for b.Loop() {
+		b.StopTimer()
+		tr := New(32, cmp.Compare[int])
+		for _, k := range keys {
+			tr.ReplaceOrInsert(k)
+		}
+		b.StartTimer()
+		for tr.Len() > 0 {
+			tr.DeleteMin()
+		}
+	}
+}
+
+func BenchmarkDeleteMin_Treap(b *testing.B) {
+	keys := benchKeys()
+	for b.Loop() {
+		b.StopTimer()
+		tr := treap.NewTreap(cmp.Compare[int])
+		for _, k := range keys {
+			tr.Insert(k)
+		}
+		b.StartTimer()
+		for !tr.IsEmpty() {
+			m, _ := tr.Min()
+			tr.Delete(m)
+		}
+	}
+}
+
+func BenchmarkDeleteMin_SkipList(b *testing.B) {
+	keys := benchKeys()
+	for b.Loop() {
+		b.StopTimer()
+		sl := skiplist.New(func(a, b int) bool { return a < b }, false)
+		for _, k := range keys {
+			sl.Insert(k)
+		}
+		b.StartTimer()
+		for sl.Len() > 0 {
+			sl.Delete(sl.GetMin())
+		}
+	}
+}
+
+// BenchmarkClone_BTree 快照 + 少量写入:写时复制只复制被改动路径上的节点
+func BenchmarkClone_BTree(b *testing.B) {
+	keys := benchKeys()
+	tr := New(32, cmp.Compare[int])
+	for _, k := range keys {
+		tr.ReplaceOrInsert(k)
+	}
+	i := 0
+	for b.Loop() {
+		snap := tr.Clone()
+		snap.ReplaceOrInsert(keys[i%benchSize] + 1)
+		i++
+	}
+}
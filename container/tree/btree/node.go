@@ -0,0 +1,269 @@
+package btree
+
+import "slices"
+
+// cowContext 是写时复制的归属标记:节点的 cow 与树的 cow 指向同一对象时,节点归该树独占,
+// 可以原地修改;否则节点与其它快照共享,必须先复制。
+// 比较器也挂在这里,节点因此无需单独保存。
+type cowContext[T any] struct {
+	cmp func(a, b T) int
+}
+
+func (c *cowContext[T]) newNode() *node[T] {
+	return &node[T]{cow: c}
+}
+
+// node 是 B 树节点:items 升序存放,内部节点有 len(items)+1 个孩子,
+// children[i] 中的元素都介于 items[i-1] 与 items[i] 之间。
+type node[T any] struct {
+	items    []T
+	children []*node[T]
+	cow      *cowContext[T]
+}
+
+// toRemove 区分删除指定元素、删除最小元素与删除最大元素。
+type toRemove int
+
+const (
+	removeItem toRemove = iota
+	removeMin
+	removeMax
+)
+
+// mutableFor 返回归 cow 独占、可以原地修改的节点:已归属时返回自身,否则复制一份。
+func (n *node[T]) mutableFor(cow *cowContext[T]) *node[T] {
+	if n.cow == cow {
+		return n
+	}
+	out := cow.newNode()
+	out.items = append(make([]T, 0, cap(n.items)), n.items...)
+	if len(n.children) > 0 {
+		out.children = append(make([]*node[T], 0, cap(n.children)), n.children...)
+	}
+	return out
+}
+
+// mutableChild 把第 i 个孩子替换为可修改的版本并返回。
+func (n *node[T]) mutableChild(i int) *node[T] {
+	c := n.children[i].mutableFor(n.cow)
+	n.children[i] = c
+	return c
+}
+
+// find 二分查找 item:命中时返回其下标;否则返回应插入的位置(即应进入的孩子下标)。
+func (n *node[T]) find(item T) (int, bool) {
+	return slices.BinarySearchFunc(n.items, item, n.cow.cmp)
+}
+
+// split 以 items[i] 为界把节点一分为二:n 保留 items[:i],返回 items[i] 与持有 items[i+1:] 的新节点。
+func (n *node[T]) split(i int) (T, *node[T]) {
+	item := n.items[i]
+	next := n.cow.newNode()
+	next.items = append(next.items, n.items[i+1:]...)
+	n.items = truncate(n.items, i)
+	if len(n.children) > 0 {
+		next.children = append(next.children, n.children[i+1:]...)
+		n.children = truncate(n.children, i+1)
+	}
+	return item, next
+}
+
+// maybeSplitChild 在第 i 个孩子已满时把它分裂,中间元素上移到 n;返回是否分裂。
+func (n *node[T]) maybeSplitChild(i, maxItems int) bool {
+	if len(n.children[i].items) < maxItems {
+		return false
+	}
+	first := n.mutableChild(i)
+	item, second := first.split(maxItems / 2)
+	n.items = slices.Insert(n.items, i, item)
+	n.children = slices.Insert(n.children, i+1, second)
+	return true
+}
+
+// insert 把 item 插入以 n 为根的子树;调用方保证 n 可修改且未满。
+// 自上而下预先分裂满节点,因此插入路径上不需要回溯。
+func (n *node[T]) insert(item T, maxItems int) (T, bool) {
+	i, found := n.find(item)
+	if found {
+		out := n.items[i]
+		n.items[i] = item
+		return out, true
+	}
+	if len(n.children) == 0 {
+		n.items = slices.Insert(n.items, i, item)
+		var zero T
+		return zero, false
+	}
+	if n.maybeSplitChild(i, maxItems) {
+		// 上移的中间元素可能正好等于 item,或者 item 应进入分裂出的右半
+		switch c := n.cow.cmp(item, n.items[i]); {
+		case c > 0:
+			i++
+		case c == 0:
+			out := n.items[i]
+			n.items[i] = item
+			return out, true
+		}
+	}
+	return n.mutableChild(i).insert(item, maxItems)
+}
+
+// remove 从以 n 为根的子树中删除;调用方保证 n 可修改,且 n 不是根时至少有 minItems+1 个元素。
+func (n *node[T]) remove(item T, minItems int, typ toRemove) (T, bool) {
+	var i int
+	var found bool
+	switch typ {
+	case removeMax:
+		if len(n.children) == 0 {
+			out := n.items[len(n.items)-1]
+			n.items = truncate(n.items, len(n.items)-1)
+			return out, true
+		}
+		i = len(n.items)
+	case removeMin:
+		if len(n.children) == 0 {
+			out := n.items[0]
+			n.items = removeAt(n.items, 0)
+			return out, true
+		}
+		i = 0
+	case removeItem:
+		i, found = n.find(item)
+		if len(n.children) == 0 {
+			if found {
+				out := n.items[i]
+				n.items = removeAt(n.items, i)
+				return out, true
+			}
+			var zero T
+			return zero, false
+		}
+	}
+
+	// 内部节点:将要进入的孩子元素太少时先向兄弟借或与兄弟合并,再重试
+	if len(n.children[i].items) <= minItems {
+		return n.growChildAndRemove(i, item, minItems, typ)
+	}
+	child := n.mutableChild(i)
+	if found {
+		// 命中内部节点:用左子树的最大元素(中序前驱)顶替
+		out := n.items[i]
+		var zero T
+		n.items[i], _ = child.remove(zero, minItems, removeMax)
+		return out, true
+	}
+	return child.remove(item, minItems, typ)
+}
+
+// growChildAndRemove 让第 i 个孩子多出一个元素后重新执行 remove:
+// 优先从左兄弟借,其次从右兄弟借,都不够时与相邻兄弟合并。
+func (n *node[T]) growChildAndRemove(i int, item T, minItems int, typ toRemove) (T, bool) {
+	switch {
+	case i > 0 && len(n.children[i-1].items) > minItems:
+		// 从左兄弟借:左兄弟最大元素上移,分隔元素下移到孩子头部
+		child := n.mutableChild(i)
+		stealFrom := n.mutableChild(i - 1)
+		stolen := stealFrom.items[len(stealFrom.items)-1]
+		stealFrom.items = truncate(stealFrom.items, len(stealFrom.items)-1)
+		child.items = slices.Insert(child.items, 0, n.items[i-1])
+		n.items[i-1] = stolen
+		if len(stealFrom.children) > 0 {
+			moved := stealFrom.children[len(stealFrom.children)-1]
+			stealFrom.children = truncate(stealFrom.children, len(stealFrom.children)-1)
+			child.children = slices.Insert(child.children, 0, moved)
+		}
+	case i < len(n.items) && len(n.children[i+1].items) > minItems:
+		// 从右兄弟借:镜像操作
+		child := n.mutableChild(i)
+		stealFrom := n.mutableChild(i + 1)
+		stolen := stealFrom.items[0]
+		stealFrom.items = removeAt(stealFrom.items, 0)
+		child.items = append(child.items, n.items[i])
+		n.items[i] = stolen
+		if len(stealFrom.children) > 0 {
+			moved := stealFrom.children[0]
+			stealFrom.children = removeAt(stealFrom.children, 0)
+			child.children = append(child.children, moved)
+		}
+	default:
+		// 与右兄弟合并(最后一个孩子则与左兄弟合并):分隔元素下移到合并后的节点中间
+		if i >= len(n.items) {
+			i--
+		}
+		child := n.mutableChild(i)
+		mergeItem := n.items[i]
+		mergeChild := n.children[i+1]
+		n.items = removeAt(n.items, i)
+		n.children = removeAt(n.children, i+1)
+		child.items = append(child.items, mergeItem)
+		child.items = append(child.items, mergeChild.items...)
+		child.children = append(child.children, mergeChild.children...)
+	}
+	return n.remove(item, minItems, typ)
+}
+
+// ascend 按升序遍历子树中落在 [low, high] 内的元素(nil 表示该端无界);返回 false 表示应停止。
+func (n *node[T]) ascend(low, high *T, fn func(T) bool) bool {
+	cmp := n.cow.cmp
+	i := 0
+	if low != nil {
+		i, _ = n.find(*low)
+	}
+	for ; i < len(n.items); i++ {
+		if len(n.children) > 0 && !n.children[i].ascend(low, high, fn) {
+			return false
+		}
+		if high != nil && cmp(n.items[i], *high) > 0 {
+			return false
+		}
+		if !fn(n.items[i]) {
+			return false
+		}
+		// items[i] >= low,其右侧的孩子不再需要下界
+		low = nil
+	}
+	if len(n.children) > 0 {
+		return n.children[len(n.items)].ascend(low, high, fn)
+	}
+	return true
+}
+
+// descend 按降序遍历子树中落在 [low, high] 内的元素;返回 false 表示应停止。
+func (n *node[T]) descend(low, high *T, fn func(T) bool) bool {
+	cmp := n.cow.cmp
+	j := len(n.items)
+	if high != nil {
+		var found bool
+		if j, found = n.find(*high); found {
+			j++
+		}
+	}
+	// items[:j] 都 <= high;children[j] 中可能还有 <= high 的元素
+	if len(n.children) > 0 && !n.children[j].descend(low, high, fn) {
+		return false
+	}
+	for i := j - 1; i >= 0; i-- {
+		if low != nil && cmp(n.items[i], *low) < 0 {
+			return false
+		}
+		if !fn(n.items[i]) {
+			return false
+		}
+		high = nil
+		if len(n.children) > 0 && !n.children[i].descend(low, high, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// truncate 截断到前 n 个元素,并清零被截掉的槽位,避免底层数组继续引用已删除的元素。
+func truncate[E any](s []E, n int) []E {
+	clear(s[n:])
+	return s[:n]
+}
+
+// removeAt 删除下标 i 的元素(保持顺序),并清零空出的末尾槽位。
+func removeAt[E any](s []E, i int) []E {
+	return slices.Delete(s, i, i+1)
+}
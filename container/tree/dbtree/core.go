@@ -1,50 +1,117 @@
 package dbtree
 
 import (
-	"github.com/leoheung/go-patterns/container/list"
+	"fmt"
+	"slices"
+	"strings"
 )
 
+// CycleError 表示依赖图中存在环;Path 首尾相同,例如 [a b c a] 表示 a→b→c→a。
+type CycleError struct {
+	Path []string
+}
 
-func contains(seen *list.List[string], item string) bool{
-	return seen.Includes(item, func(a, b string) bool {return a == b})
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("dependency cycle detected: %s", strings.Join(e.Path, "→"))
 }
 
+// 深度优先遍历时的节点状态
+const (
+	unvisited = iota
+	visiting  // 仍在当前 DFS 路径上
+	visited   // 已输出
+)
 
-func BuildDependencyOrder(tables []string, tablesDependencies map[string][]string) []string {
+// BuildDependencyOrder 返回建表顺序:每张表都排在它依赖的表之后。
+// tablesDependencies[t] 列出 t 依赖的表;只出现在依赖中的表同样会被输出。
+// 结果是确定的:按 tables 的顺序深度优先,依赖按声明顺序展开。
+// 依赖图中有环时返回 *CycleError,其中包含完整的环路径。
+func BuildDependencyOrder(tables []string, tablesDependencies map[string][]string) ([]string, error) {
 	if len(tables) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	seen := list.New[string]()
-	stack := list.From(tables)
-	
-	for temp, ok := stack.Peek(); ok ; temp,ok = stack.Peek(){
-		if contains(seen, temp) {
-			stack.Pop()
-			continue
+	state := make(map[string]int)
+	order := make([]string, 0, len(tables))
+	path := make([]string, 0)
+
+	var visit func(table string) error
+	visit = func(table string) error {
+		switch state[table] {
+		case visited:
+			return nil
+		case visiting:
+			// 从路径中第一次出现 table 的位置截出环
+			start := slices.Index(path, table)
+			cycle := append(slices.Clone(path[start:]), table)
+			return &CycleError{Path: cycle}
 		}
 
-		deps, ok := tablesDependencies[temp]
-		if (!ok || len(deps) == 0) {
-			if !contains(seen, temp) {
-				seen.Append(temp)
-				stack.Pop()
-			}
-		} else {
-			done := true
-			for _, dep := range deps {
-				if !contains(seen, dep) {
-					stack.Push(dep)
-					done = false
-				}
+		state[table] = visiting
+		path = append(path, table)
+		for _, dep := range tablesDependencies[table] {
+			if err := visit(dep); err != nil {
+				return err
 			}
+		}
+		path = path[:len(path)-1]
+		state[table] = visited
+		order = append(order, table)
+		return nil
+	}
 
-			if done {
-				stack.Pop()
-				seen.Append(temp)
-			}
+	for _, table := range tables {
+		if err := visit(table); err != nil {
+			return nil, err
 		}
 	}
+	return order, nil
+}
 
-	return seen.ToSlice()
+// BuildDependencyLevels 把表分成若干层:第 0 层不依赖任何表,第 k 层的表只依赖前 k 层中的表,
+// 且至少依赖一张第 k-1 层的表。同一层内的表互不依赖,迁移 / 灌数时可以逐层并发执行。
+// 层内顺序与 BuildDependencyOrder 的结果一致;有环时返回 *CycleError。
+func BuildDependencyLevels(tables []string, tablesDependencies map[string][]string) ([][]string, error) {
+	order, err := BuildDependencyOrder(tables, tablesDependencies)
+	if err != nil || len(order) == 0 {
+		return nil, err
+	}
+
+	// order 中依赖总是先于依赖方出现,一次顺序扫描即可求出每张表的层号
+	level := make(map[string]int, len(order))
+	levels := make([][]string, 0)
+	for _, table := range order {
+		lv := 0
+		for _, dep := range tablesDependencies[table] {
+			lv = max(lv, level[dep]+1)
+		}
+		level[table] = lv
+		if lv == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[lv] = append(levels[lv], table)
+	}
+	return levels, nil
+}
+
+// ReverseDependencyOrder 返回删表 / 清理顺序:每张表都排在依赖它的表之后,
+// 即 BuildDependencyOrder 结果的逆序。
+func ReverseDependencyOrder(tables []string, tablesDependencies map[string][]string) ([]string, error) {
+	order, err := BuildDependencyOrder(tables, tablesDependencies)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(order)
+	return order, nil
+}
+
+// ReverseDependencyLevels 返回清理用的分层结果,即 BuildDependencyLevels 的层逆序:
+// 先并发清理最外层(没有被任何表依赖)的表,最后清理第 0 层。
+func ReverseDependencyLevels(tables []string, tablesDependencies map[string][]string) ([][]string, error) {
+	levels, err := BuildDependencyLevels(tables, tablesDependencies)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(levels)
+	return levels, nil
 }
@@ -0,0 +1,377 @@
+package dbtree
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Dialect 指定 DDL 的 SQL 方言,影响引号、注释与未加引号标识符的大小写处理。
+type Dialect int
+
+const (
+	// PostgreSQL:"ident" 为带引号标识符,未加引号的标识符折叠为小写,支持 $tag$...$tag$ 字符串。
+	PostgreSQL Dialect = iota
+	// MySQL:`ident` 为带引号标识符,未加引号的标识符保持原样,支持 # 注释与字符串中的反斜杠转义。
+	MySQL
+)
+
+// DDLOptions 控制 ParseDDL 的行为。
+type DDLOptions struct {
+	Dialect Dialect
+	// DefaultSchema 非空时,该 schema 下的表以不带 schema 的名字输出,
+	// 使 public.users 与 users 视为同一张表(PostgreSQL 通常为 "public")。
+	DefaultSchema string
+}
+
+// Schema 是从 DDL 中提取出的表依赖关系。表名形如 table 或 schema.table。
+type Schema struct {
+	// Tables 按 DDL 中首次出现的顺序列出 CREATE TABLE / ALTER TABLE 涉及的表
+	Tables []string
+	// Dependencies[t] 为 t 通过 FOREIGN KEY / REFERENCES 引用的表(去重,不含 t 自身)
+	Dependencies map[string][]string
+	// SelfReferences 为引用自身的表(如 parent_id 指向本表);
+	// 它们不参与排序,但插入数据时需要 DEFERRABLE 约束或分两步写入
+	SelfReferences []string
+	// External 为被引用但 DDL 中没有声明的表
+	External []string
+}
+
+// Order 返回建表顺序,等价于 BuildDependencyOrder(s.Tables, s.Dependencies)。
+func (s *Schema) Order() ([]string, error) {
+	return BuildDependencyOrder(s.Tables, s.Dependencies)
+}
+
+// Levels 返回可逐层并发建表的分层结果。
+func (s *Schema) Levels() ([][]string, error) {
+	return BuildDependencyLevels(s.Tables, s.Dependencies)
+}
+
+// ReverseOrder 返回删表顺序。
+func (s *Schema) ReverseOrder() ([]string, error) {
+	return ReverseDependencyOrder(s.Tables, s.Dependencies)
+}
+
+// ParseDDL 解析 CREATE TABLE / ALTER TABLE 语句,提取其中全部 REFERENCES 子句
+// (列级 REFERENCES t(col) 与表级 FOREIGN KEY (...) REFERENCES t(...) 均支持)。
+// 其余语句(CREATE INDEX / CREATE FUNCTION / INSERT ...)被忽略。
+// 遇到未闭合的字符串、注释或带引号标识符,或 REFERENCES 后缺少表名时返回错误。
+func ParseDDL(ddl string, opts DDLOptions) (*Schema, error) {
+	tokens, err := lexDDL(ddl, opts.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schema{Dependencies: make(map[string][]string)}
+	declared := make(map[string]bool)
+	referenced := make([]string, 0)
+
+	for _, stmt := range splitStatements(tokens) {
+		p := &ddlParser{tokens: stmt, opts: opts}
+		table, ok, err := p.tableStatement()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if !declared[table] {
+			declared[table] = true
+			s.Tables = append(s.Tables, table)
+		}
+
+		for p.skipTo("REFERENCES") {
+			line := p.tokens[p.pos].line
+			p.pos++
+			ref, ok := p.qualifiedName()
+			if !ok {
+				return nil, fmt.Errorf("failed to parse DDL: line %d: REFERENCES in table %s is not followed by a table name", line, table)
+			}
+			if ref == table {
+				if !slices.Contains(s.SelfReferences, table) {
+					s.SelfReferences = append(s.SelfReferences, table)
+				}
+				continue
+			}
+			if !slices.Contains(s.Dependencies[table], ref) {
+				s.Dependencies[table] = append(s.Dependencies[table], ref)
+				referenced = append(referenced, ref)
+			}
+		}
+	}
+
+	for _, ref := range referenced {
+		if !declared[ref] && !slices.Contains(s.External, ref) {
+			s.External = append(s.External, ref)
+		}
+	}
+	return s, nil
+}
+
+// ═══════════════════════════════════════════════════════
+// 语句解析
+// ═══════════════════════════════════════════════════════
+
+type ddlParser struct {
+	tokens []ddlToken
+	pos    int
+	opts   DDLOptions
+}
+
+// tableStatement 识别 CREATE TABLE / ALTER TABLE 并返回表名;其它语句返回 ok == false。
+func (p *ddlParser) tableStatement() (string, bool, error) {
+	switch {
+	case p.acceptKeyword("CREATE"):
+		p.acceptKeyword("OR")
+		p.acceptKeyword("REPLACE")
+		for p.acceptKeyword("GLOBAL") || p.acceptKeyword("LOCAL") || p.acceptKeyword("TEMPORARY") ||
+			p.acceptKeyword("TEMP") || p.acceptKeyword("UNLOGGED") {
+		}
+		if !p.acceptKeyword("TABLE") {
+			return "", false, nil
+		}
+		if p.acceptKeyword("IF") {
+			p.acceptKeyword("NOT")
+			p.acceptKeyword("EXISTS")
+		}
+	case p.acceptKeyword("ALTER"):
+		if !p.acceptKeyword("TABLE") {
+			return "", false, nil
+		}
+		if p.acceptKeyword("IF") {
+			p.acceptKeyword("EXISTS")
+		}
+		p.acceptKeyword("ONLY")
+	default:
+		return "", false, nil
+	}
+
+	line := 0
+	if p.pos < len(p.tokens) {
+		line = p.tokens[p.pos].line
+	}
+	name, ok := p.qualifiedName()
+	if !ok {
+		return "", false, fmt.Errorf("failed to parse DDL: line %d: missing table name", line)
+	}
+	return name, true, nil
+}
+
+// acceptKeyword 当前 token 为未加引号的 kw(不区分大小写)时消费它并返回 true。
+func (p *ddlParser) acceptKeyword(kw string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].isKeyword(kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipTo 前进到下一个关键字 kw(不消费);不存在时返回 false。
+func (p *ddlParser) skipTo(kw string) bool {
+	for ; p.pos < len(p.tokens); p.pos++ {
+		if p.tokens[p.pos].isKeyword(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifiedName 读取 ident(.ident)* 并按方言规范化;DefaultSchema 下的表去掉 schema 前缀。
+func (p *ddlParser) qualifiedName() (string, bool) {
+	parts := make([]string, 0, 2)
+	for {
+		if p.pos >= len(p.tokens) {
+			break
+		}
+		t := p.tokens[p.pos]
+		if t.kind != tokIdent && t.kind != tokQuotedIdent {
+			break
+		}
+		parts = append(parts, p.normalize(t))
+		p.pos++
+		if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokPunct && p.tokens[p.pos].text == "." {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	if len(parts) == 2 && p.opts.DefaultSchema != "" && parts[0] == p.opts.DefaultSchema {
+		parts = parts[1:]
+	}
+	return strings.Join(parts, "."), true
+}
+
+func (p *ddlParser) normalize(t ddlToken) string {
+	if t.kind == tokIdent && p.opts.Dialect == PostgreSQL {
+		return strings.ToLower(t.text)
+	}
+	return t.text
+}
+
+// ═══════════════════════════════════════════════════════
+// 词法分析
+// ═══════════════════════════════════════════════════════
+
+type tokenKind int
+
+const (
+	tokIdent       tokenKind = iota // 未加引号的标识符 / 关键字
+	tokQuotedIdent                  // 带引号的标识符,text 为去掉引号后的内容
+	tokString                       // 字符串常量(内容不保留)
+	tokPunct                        // 单字符标点:. ( ) , ;
+	tokOther                        // 数字、运算符等
+)
+
+type ddlToken struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t ddlToken) isKeyword(kw string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, kw)
+}
+
+// splitStatements 以顶层的 ; 切分语句。
+func splitStatements(tokens []ddlToken) [][]ddlToken {
+	stmts := make([][]ddlToken, 0)
+	start := 0
+	for i, t := range tokens {
+		if t.kind == tokPunct && t.text == ";" {
+			if i > start {
+				stmts = append(stmts, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		stmts = append(stmts, tokens[start:])
+	}
+	return stmts
+}
+
+// lexDDL 把 DDL 切成 token,跳过注释与空白。
+func lexDDL(src string, dialect Dialect) ([]ddlToken, error) {
+	tokens := make([]ddlToken, 0)
+	line := 1
+	i := 0
+
+	// closeQuote 找到与 src[i] 相同的结束引号(连写两次表示转义),返回结束引号之后的下标
+	closeQuote := func(q byte, backslash bool) (int, error) {
+		startLine := line
+		for j := i + 1; j < len(src); j++ {
+			switch c := src[j]; {
+			case c == '\n':
+				line++
+			case backslash && c == '\\':
+				j++
+			case c == q:
+				if j+1 < len(src) && src[j+1] == q {
+					j++
+					continue
+				}
+				return j + 1, nil
+			}
+		}
+		return 0, fmt.Errorf("failed to parse DDL: line %d: unterminated %c", startLine, q)
+	}
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+
+		case c == '-' && strings.HasPrefix(src[i:], "--"), c == '#' && dialect == MySQL:
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("failed to parse DDL: line %d: unterminated /* comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+
+		case c == '\'':
+			end, err := closeQuote('\'', dialect == MySQL)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, ddlToken{kind: tokString, line: line})
+			i = end
+		case c == '"' && dialect == MySQL:
+			// MySQL 默认 sql_mode 下双引号是字符串
+			end, err := closeQuote('"', true)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, ddlToken{kind: tokString, line: line})
+			i = end
+		case c == '"' || (c == '`' && dialect == MySQL):
+			startLine := line
+			end, err := closeQuote(c, false)
+			if err != nil {
+				return nil, err
+			}
+			text := strings.ReplaceAll(src[i+1:end-1], string([]byte{c, c}), string(c))
+			tokens = append(tokens, ddlToken{kind: tokQuotedIdent, text: text, line: startLine})
+			i = end
+
+		case c == '$' && dialect == PostgreSQL && dollarTag(src[i:]) != "":
+			tag := dollarTag(src[i:])
+			end := strings.Index(src[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("failed to parse DDL: line %d: unterminated %s string", line, tag)
+			}
+			body := src[i : i+len(tag)+end+len(tag)]
+			tokens = append(tokens, ddlToken{kind: tokString, line: line})
+			line += strings.Count(body, "\n")
+			i += len(body)
+
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(src) && isIdentPart(src[j]) {
+				j++
+			}
+			tokens = append(tokens, ddlToken{kind: tokIdent, text: src[i:j], line: line})
+			i = j
+		case strings.IndexByte(".(),;", c) >= 0:
+			tokens = append(tokens, ddlToken{kind: tokPunct, text: string(c), line: line})
+			i++
+		default:
+			tokens = append(tokens, ddlToken{kind: tokOther, text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// dollarTag 识别 PostgreSQL 的 $$ 或 $tag$ 开头,返回完整的 tag;不是时返回空串。
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		switch c := s[j]; {
+		case c == '$':
+			return s[:j+1]
+		case isIdentPart(c) && !(j == 1 && c >= '0' && c <= '9'):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '$'
+}
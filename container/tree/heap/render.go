@@ -0,0 +1,47 @@
+package heap
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/leoheung/go-patterns/container/render"
+)
+
+// ToGraph 把堆按完全二叉树布局转换为 render.Graph:
+// 节点 ID 为 h<下标>,标签为「值 + [下标]」,下标 i 的孩子为 2i+1 与 2i+2。
+// 最后一个内部节点只有左孩子时补一个不可见的右侧占位,避免它被画在正下方。
+func (h *BinaryHeap[T]) ToGraph() *render.Graph {
+	g := render.NewGraph("BinaryHeap", render.TopDown)
+	n := len(h.data)
+	for i, v := range h.data {
+		g.AddNode(render.Node{
+			ID:    fmt.Sprintf("h%d", i),
+			Label: fmt.Sprintf("%v\n[%d]", v, i),
+		})
+	}
+	for i := range n {
+		l, r := 2*i+1, 2*i+2
+		if l >= n {
+			break
+		}
+		g.AddEdge(render.Edge{From: fmt.Sprintf("h%d", i), To: fmt.Sprintf("h%d", l)})
+		if r < n {
+			g.AddEdge(render.Edge{From: fmt.Sprintf("h%d", i), To: fmt.Sprintf("h%d", r)})
+		} else {
+			ph := fmt.Sprintf("h%d_nil", i)
+			g.AddNode(render.Node{ID: ph, Invisible: true})
+			g.AddEdge(render.Edge{From: fmt.Sprintf("h%d", i), To: ph, Invisible: true})
+		}
+	}
+	return g
+}
+
+// DrawDOT 以 Graphviz DOT 格式输出堆的树形布局。
+func (h *BinaryHeap[T]) DrawDOT(out io.Writer) error {
+	return h.ToGraph().WriteDOT(out)
+}
+
+// DrawMermaid 以 Mermaid flowchart 格式输出堆的树形布局。
+func (h *BinaryHeap[T]) DrawMermaid(out io.Writer) error {
+	return h.ToGraph().WriteMermaid(out)
+}
@@ -0,0 +1,36 @@
+package treemap
+
+import (
+	"github.com/leoheung/go-patterns/container/tree/bst"
+	"github.com/leoheung/go-patterns/container/tree/bst/avl"
+	"github.com/leoheung/go-patterns/container/tree/bst/rbtree"
+	"github.com/leoheung/go-patterns/container/tree/bst/splay"
+	"github.com/leoheung/go-patterns/container/tree/bst/treap"
+)
+
+// Backing 根据比较器创建一棵空的底层平衡树。
+// TreeMap / TreeSet 只通过 bst.SelfBalancingBST 访问底层树,任意实现都可以作为 Backing,
+// 下面的 Treap / AVL / RBTree / Splay 可直接传给 NewTreeMapWith / NewTreeSetWith:
+//
+//	m := treemap.NewTreeMapWith[int, string](cmp.Compare[int], treemap.RBTree)
+type Backing[T any] func(cmp func(a, b T) int) bst.SelfBalancingBST[T]
+
+// Treap 以 treap 作为底层树(默认)。
+func Treap[T any](cmp func(a, b T) int) bst.SelfBalancingBST[T] {
+	return treap.NewTreap(cmp)
+}
+
+// AVL 以 AVL 树作为底层树,查询路径最短,适合读多写少。
+func AVL[T any](cmp func(a, b T) int) bst.SelfBalancingBST[T] {
+	return avl.NewAVL(cmp)
+}
+
+// RBTree 以红黑树作为底层树,写入时旋转次数少。
+func RBTree[T any](cmp func(a, b T) int) bst.SelfBalancingBST[T] {
+	return rbtree.NewRBTree(cmp)
+}
+
+// Splay 以伸展树作为底层树,热点 key 访问摊还 O(1);注意查询同样会修改树结构。
+func Splay[T any](cmp func(a, b T) int) bst.SelfBalancingBST[T] {
+	return splay.NewSplayTree(cmp)
+}
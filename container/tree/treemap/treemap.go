@@ -0,0 +1,241 @@
+package treemap
+
+import (
+	"iter"
+
+	"github.com/leoheung/go-patterns/container/tree/bst"
+)
+
+// Entry 是 TreeMap 中的一个键值对。
+type Entry[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// TreeMap 按 key 有序、key 唯一的映射,构建在任意 bst.SelfBalancingBST 之上。
+//
+// 底层树保存 *Entry[K, V] 并只按 Key 比较;Put 命中已有 key 时原地替换 Value,
+// 因此底层树的多重集语义不会暴露出来。除 Len / IsEmpty 外的操作均为 O(log n)。
+//
+// TreeMap 不是并发安全的;以 Splay 为底层树时,连查询也会修改树结构。
+type TreeMap[K, V any] struct {
+	tree bst.SelfBalancingBST[*Entry[K, V]]
+	cmp  func(a, b K) int
+}
+
+// NewTreeMap 创建以 treap 为底层树的空 TreeMap。
+func NewTreeMap[K, V any](cmp func(a, b K) int) *TreeMap[K, V] {
+	return NewTreeMapWith[K, V](cmp, Treap)
+}
+
+// NewTreeMapWith 创建以 backing 为底层树的空 TreeMap。
+func NewTreeMapWith[K, V any](cmp func(a, b K) int, backing Backing[*Entry[K, V]]) *TreeMap[K, V] {
+	return &TreeMap[K, V]{
+		tree: backing(func(a, b *Entry[K, V]) int { return cmp(a.Key, b.Key) }),
+		cmp:  cmp,
+	}
+}
+
+// Put 写入 key → value。key 已存在时替换旧值并返回 (旧值, true);否则新增并返回 (zero, false)。
+func (m *TreeMap[K, V]) Put(key K, value V) (V, bool) {
+	if e, ok := m.tree.Get(m.probe(key)); ok {
+		old := e.Value
+		e.Value = value
+		return old, true
+	}
+	m.tree.Insert(&Entry[K, V]{Key: key, Value: value})
+	var zero V
+	return zero, false
+}
+
+// PutIfAbsent 仅在 key 不存在时写入,返回是否写入。
+func (m *TreeMap[K, V]) PutIfAbsent(key K, value V) bool {
+	if _, ok := m.tree.Get(m.probe(key)); ok {
+		return false
+	}
+	m.tree.Insert(&Entry[K, V]{Key: key, Value: value})
+	return true
+}
+
+// Get 返回 key 对应的值;不存在时返回 (zero, false)。
+func (m *TreeMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := m.tree.Get(m.probe(key)); ok {
+		return e.Value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// ContainsKey 判断 key 是否存在。
+func (m *TreeMap[K, V]) ContainsKey(key K) bool {
+	_, ok := m.tree.Get(m.probe(key))
+	return ok
+}
+
+// Delete 删除 key,返回被删除的值;不存在时返回 (zero, false)。
+func (m *TreeMap[K, V]) Delete(key K) (V, bool) {
+	p := m.probe(key)
+	e, ok := m.tree.Get(p)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	m.tree.Delete(p)
+	return e.Value, true
+}
+
+// Len 返回键值对个数。
+func (m *TreeMap[K, V]) Len() int {
+	return m.tree.Size()
+}
+
+// IsEmpty 判断映射是否为空。
+func (m *TreeMap[K, V]) IsEmpty() bool {
+	return m.tree.IsEmpty()
+}
+
+// Clear 清空映射。
+func (m *TreeMap[K, V]) Clear() {
+	m.tree.Clear()
+}
+
+// First 返回 key 最小的键值对;空映射返回 (zero, false)。
+func (m *TreeMap[K, V]) First() (Entry[K, V], bool) {
+	return entryOf(m.tree.Min())
+}
+
+// Last 返回 key 最大的键值对;空映射返回 (zero, false)。
+func (m *TreeMap[K, V]) Last() (Entry[K, V], bool) {
+	return entryOf(m.tree.Max())
+}
+
+// FirstKey 返回最小的 key;空映射返回 (zero, false)。
+func (m *TreeMap[K, V]) FirstKey() (K, bool) {
+	e, ok := m.First()
+	return e.Key, ok
+}
+
+// LastKey 返回最大的 key;空映射返回 (zero, false)。
+func (m *TreeMap[K, V]) LastKey() (K, bool) {
+	e, ok := m.Last()
+	return e.Key, ok
+}
+
+// PopFirst 删除并返回 key 最小的键值对;空映射返回 (zero, false)。
+func (m *TreeMap[K, V]) PopFirst() (Entry[K, V], bool) {
+	e, ok := m.tree.Min()
+	return m.pop(e, ok)
+}
+
+// PopLast 删除并返回 key 最大的键值对;空映射返回 (zero, false)。
+func (m *TreeMap[K, V]) PopLast() (Entry[K, V], bool) {
+	e, ok := m.tree.Max()
+	return m.pop(e, ok)
+}
+
+// Floor 返回 key <= 给定 key 的最大键值对;不存在时返回 (zero, false)。
+func (m *TreeMap[K, V]) Floor(key K) (Entry[K, V], bool) {
+	return entryOf(m.floor(key))
+}
+
+// Ceiling 返回 key >= 给定 key 的最小键值对;不存在时返回 (zero, false)。
+func (m *TreeMap[K, V]) Ceiling(key K) (Entry[K, V], bool) {
+	return entryOf(m.ceiling(key))
+}
+
+// Lower 返回 key < 给定 key 的最大键值对;不存在时返回 (zero, false)。
+func (m *TreeMap[K, V]) Lower(key K) (Entry[K, V], bool) {
+	return entryOf(m.tree.Predecessor(m.probe(key)))
+}
+
+// Higher 返回 key > 给定 key 的最小键值对;不存在时返回 (zero, false)。
+func (m *TreeMap[K, V]) Higher(key K) (Entry[K, V], bool) {
+	return entryOf(m.tree.Successor(m.probe(key)))
+}
+
+// Keys 按升序返回全部 key。
+func (m *TreeMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.tree.Size())
+	m.tree.InOrderTraverse(func(e *Entry[K, V]) {
+		keys = append(keys, e.Key)
+	})
+	return keys
+}
+
+// Values 按 key 升序返回全部值。
+func (m *TreeMap[K, V]) Values() []V {
+	values := make([]V, 0, m.tree.Size())
+	m.tree.InOrderTraverse(func(e *Entry[K, V]) {
+		values = append(values, e.Value)
+	})
+	return values
+}
+
+// All 按 key 升序迭代全部键值对。
+func (m *TreeMap[K, V]) All() iter.Seq2[K, V] {
+	return m.unbounded().All()
+}
+
+// Backward 按 key 降序迭代全部键值对。
+func (m *TreeMap[K, V]) Backward() iter.Seq2[K, V] {
+	return m.unbounded().Backward()
+}
+
+// SubMap 返回 key ∈ [from, to) 的视图。
+func (m *TreeMap[K, V]) SubMap(from, to K) *MapView[K, V] {
+	return &MapView[K, V]{m: m, lo: from, hasLo: true, hi: to, hasHi: true}
+}
+
+// HeadMap 返回 key < to 的视图。
+func (m *TreeMap[K, V]) HeadMap(to K) *MapView[K, V] {
+	return &MapView[K, V]{m: m, hi: to, hasHi: true}
+}
+
+// TailMap 返回 key >= from 的视图。
+func (m *TreeMap[K, V]) TailMap(from K) *MapView[K, V] {
+	return &MapView[K, V]{m: m, lo: from, hasLo: true}
+}
+
+// probe 构造只携带 key 的查询样板。
+func (m *TreeMap[K, V]) probe(key K) *Entry[K, V] {
+	return &Entry[K, V]{Key: key}
+}
+
+// floor 返回 key <= 给定 key 的最大节点。
+func (m *TreeMap[K, V]) floor(key K) (*Entry[K, V], bool) {
+	p := m.probe(key)
+	if e, ok := m.tree.Get(p); ok {
+		return e, true
+	}
+	return m.tree.Predecessor(p)
+}
+
+// ceiling 返回 key >= 给定 key 的最小节点。
+func (m *TreeMap[K, V]) ceiling(key K) (*Entry[K, V], bool) {
+	p := m.probe(key)
+	if e, ok := m.tree.Get(p); ok {
+		return e, true
+	}
+	return m.tree.Successor(p)
+}
+
+func (m *TreeMap[K, V]) pop(e *Entry[K, V], ok bool) (Entry[K, V], bool) {
+	if !ok {
+		return Entry[K, V]{}, false
+	}
+	m.tree.Delete(e)
+	return *e, true
+}
+
+// unbounded 返回覆盖整个映射的视图,迭代逻辑与 MapView 共用。
+func (m *TreeMap[K, V]) unbounded() *MapView[K, V] {
+	return &MapView[K, V]{m: m}
+}
+
+// entryOf 把底层树返回的 *Entry 复制为值,避免调用方绕过 TreeMap 修改 Key。
+func entryOf[K, V any](e *Entry[K, V], ok bool) (Entry[K, V], bool) {
+	if !ok {
+		return Entry[K, V]{}, false
+	}
+	return *e, true
+}
@@ -0,0 +1,215 @@
+package treemap
+
+import "iter"
+
+// TreeSet 有序、元素唯一的集合,是 TreeMap[T, struct{}] 的薄封装。
+// 与底层 bst.SelfBalancingBST 的多重集语义不同,重复 Add 会被拒绝。
+type TreeSet[T any] struct {
+	m *TreeMap[T, struct{}]
+}
+
+// NewTreeSet 创建以 treap 为底层树的空 TreeSet。
+func NewTreeSet[T any](cmp func(a, b T) int) *TreeSet[T] {
+	return &TreeSet[T]{m: NewTreeMap[T, struct{}](cmp)}
+}
+
+// NewTreeSetWith 创建以 backing 为底层树的空 TreeSet。
+func NewTreeSetWith[T any](cmp func(a, b T) int, backing Backing[*Entry[T, struct{}]]) *TreeSet[T] {
+	return &TreeSet[T]{m: NewTreeMapWith[T, struct{}](cmp, backing)}
+}
+
+// Add 加入 item;已存在相等元素时不做修改并返回 false。
+func (s *TreeSet[T]) Add(item T) bool {
+	return s.m.PutIfAbsent(item, struct{}{})
+}
+
+// Contains 判断 item 是否存在。
+func (s *TreeSet[T]) Contains(item T) bool {
+	return s.m.ContainsKey(item)
+}
+
+// Remove 删除 item,返回是否删除。
+func (s *TreeSet[T]) Remove(item T) bool {
+	_, ok := s.m.Delete(item)
+	return ok
+}
+
+// Len 返回元素个数。
+func (s *TreeSet[T]) Len() int {
+	return s.m.Len()
+}
+
+// IsEmpty 判断集合是否为空。
+func (s *TreeSet[T]) IsEmpty() bool {
+	return s.m.IsEmpty()
+}
+
+// Clear 清空集合。
+func (s *TreeSet[T]) Clear() {
+	s.m.Clear()
+}
+
+// First 返回最小元素。
+func (s *TreeSet[T]) First() (T, bool) {
+	return s.m.FirstKey()
+}
+
+// Last 返回最大元素。
+func (s *TreeSet[T]) Last() (T, bool) {
+	return s.m.LastKey()
+}
+
+// PopFirst 删除并返回最小元素。
+func (s *TreeSet[T]) PopFirst() (T, bool) {
+	return keyOf(s.m.PopFirst())
+}
+
+// PopLast 删除并返回最大元素。
+func (s *TreeSet[T]) PopLast() (T, bool) {
+	return keyOf(s.m.PopLast())
+}
+
+// Floor 返回 <= item 的最大元素。
+func (s *TreeSet[T]) Floor(item T) (T, bool) {
+	return keyOf(s.m.Floor(item))
+}
+
+// Ceiling 返回 >= item 的最小元素。
+func (s *TreeSet[T]) Ceiling(item T) (T, bool) {
+	return keyOf(s.m.Ceiling(item))
+}
+
+// Lower 返回 < item 的最大元素。
+func (s *TreeSet[T]) Lower(item T) (T, bool) {
+	return keyOf(s.m.Lower(item))
+}
+
+// Higher 返回 > item 的最小元素。
+func (s *TreeSet[T]) Higher(item T) (T, bool) {
+	return keyOf(s.m.Higher(item))
+}
+
+// Items 按升序返回全部元素。
+func (s *TreeSet[T]) Items() []T {
+	return s.m.Keys()
+}
+
+// All 按升序迭代全部元素。
+func (s *TreeSet[T]) All() iter.Seq[T] {
+	return keysOf(s.m.All())
+}
+
+// Backward 按降序迭代全部元素。
+func (s *TreeSet[T]) Backward() iter.Seq[T] {
+	return keysOf(s.m.Backward())
+}
+
+// SubSet 返回元素 ∈ [from, to) 的视图。
+func (s *TreeSet[T]) SubSet(from, to T) *SetView[T] {
+	return &SetView[T]{v: s.m.SubMap(from, to)}
+}
+
+// HeadSet 返回元素 < to 的视图。
+func (s *TreeSet[T]) HeadSet(to T) *SetView[T] {
+	return &SetView[T]{v: s.m.HeadMap(to)}
+}
+
+// TailSet 返回元素 >= from 的视图。
+func (s *TreeSet[T]) TailSet(from T) *SetView[T] {
+	return &SetView[T]{v: s.m.TailMap(from)}
+}
+
+// SetView 是 TreeSet 在区间上的实时视图,语义同 MapView。
+type SetView[T any] struct {
+	v *MapView[T, struct{}]
+}
+
+// Contains 判断区间内是否存在 item。
+func (sv *SetView[T]) Contains(item T) bool {
+	return sv.v.ContainsKey(item)
+}
+
+// Remove 删除区间内的 item。
+func (sv *SetView[T]) Remove(item T) bool {
+	_, ok := sv.v.Delete(item)
+	return ok
+}
+
+// Len 返回区间内元素个数。
+func (sv *SetView[T]) Len() int {
+	return sv.v.Len()
+}
+
+// IsEmpty 判断区间内是否没有元素。
+func (sv *SetView[T]) IsEmpty() bool {
+	return sv.v.IsEmpty()
+}
+
+// First 返回区间内最小元素。
+func (sv *SetView[T]) First() (T, bool) {
+	return sv.v.FirstKey()
+}
+
+// Last 返回区间内最大元素。
+func (sv *SetView[T]) Last() (T, bool) {
+	return sv.v.LastKey()
+}
+
+// PopFirst 删除并返回区间内最小元素。
+func (sv *SetView[T]) PopFirst() (T, bool) {
+	return keyOf(sv.v.PopFirst())
+}
+
+// PopLast 删除并返回区间内最大元素。
+func (sv *SetView[T]) PopLast() (T, bool) {
+	return keyOf(sv.v.PopLast())
+}
+
+// Floor 返回区间内 <= item 的最大元素。
+func (sv *SetView[T]) Floor(item T) (T, bool) {
+	return keyOf(sv.v.Floor(item))
+}
+
+// Ceiling 返回区间内 >= item 的最小元素。
+func (sv *SetView[T]) Ceiling(item T) (T, bool) {
+	return keyOf(sv.v.Ceiling(item))
+}
+
+// Lower 返回区间内 < item 的最大元素。
+func (sv *SetView[T]) Lower(item T) (T, bool) {
+	return keyOf(sv.v.Lower(item))
+}
+
+// Higher 返回区间内 > item 的最小元素。
+func (sv *SetView[T]) Higher(item T) (T, bool) {
+	return keyOf(sv.v.Higher(item))
+}
+
+// Items 按升序返回区间内全部元素。
+func (sv *SetView[T]) Items() []T {
+	return sv.v.Keys()
+}
+
+// All 按升序迭代区间内元素。
+func (sv *SetView[T]) All() iter.Seq[T] {
+	return keysOf(sv.v.All())
+}
+
+// Backward 按降序迭代区间内元素。
+func (sv *SetView[T]) Backward() iter.Seq[T] {
+	return keysOf(sv.v.Backward())
+}
+
+func keyOf[T any](e Entry[T, struct{}], ok bool) (T, bool) {
+	return e.Key, ok
+}
+
+func keysOf[T any](seq iter.Seq2[T, struct{}]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := range seq {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
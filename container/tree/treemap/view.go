@@ -0,0 +1,205 @@
+package treemap
+
+import "iter"
+
+// MapView 是 TreeMap 在 key 区间 [lo, hi) 上的实时视图(任一端可以无界),
+// 由 SubMap / HeadMap / TailMap 创建。
+//
+// 视图不复制数据:对原 TreeMap 的修改会立即反映到视图中,
+// 通过视图执行的 Delete / PopFirst / PopLast 也会直接作用于原 TreeMap。
+// 视图不提供写入;区间外的 key 在视图中视为不存在。
+type MapView[K, V any] struct {
+	m     *TreeMap[K, V]
+	lo    K // 下界(含),hasLo 为 false 时无界
+	hasLo bool
+	hi    K // 上界(不含),hasHi 为 false 时无界
+	hasHi bool
+}
+
+// Get 返回区间内 key 对应的值。
+func (v *MapView[K, V]) Get(key K) (V, bool) {
+	if !v.inRange(key) {
+		var zero V
+		return zero, false
+	}
+	return v.m.Get(key)
+}
+
+// ContainsKey 判断区间内是否存在 key。
+func (v *MapView[K, V]) ContainsKey(key K) bool {
+	return v.inRange(key) && v.m.ContainsKey(key)
+}
+
+// Delete 删除区间内的 key;区间外的 key 不受影响。
+func (v *MapView[K, V]) Delete(key K) (V, bool) {
+	if !v.inRange(key) {
+		var zero V
+		return zero, false
+	}
+	return v.m.Delete(key)
+}
+
+// Len 返回区间内的键值对个数,借助底层树的 Rank 在 O(log n) 内算出。
+func (v *MapView[K, V]) Len() int {
+	n := v.m.tree.Size()
+	if v.hasHi {
+		n = v.m.tree.Rank(v.m.probe(v.hi))
+	}
+	if v.hasLo {
+		n -= v.m.tree.Rank(v.m.probe(v.lo))
+	}
+	return max(n, 0)
+}
+
+// IsEmpty 判断区间内是否没有键值对。
+func (v *MapView[K, V]) IsEmpty() bool {
+	_, ok := v.first()
+	return !ok
+}
+
+// First 返回区间内 key 最小的键值对。
+func (v *MapView[K, V]) First() (Entry[K, V], bool) {
+	return entryOf(v.first())
+}
+
+// Last 返回区间内 key 最大的键值对。
+func (v *MapView[K, V]) Last() (Entry[K, V], bool) {
+	return entryOf(v.last())
+}
+
+// FirstKey 返回区间内最小的 key。
+func (v *MapView[K, V]) FirstKey() (K, bool) {
+	e, ok := v.First()
+	return e.Key, ok
+}
+
+// LastKey 返回区间内最大的 key。
+func (v *MapView[K, V]) LastKey() (K, bool) {
+	e, ok := v.Last()
+	return e.Key, ok
+}
+
+// PopFirst 删除并返回区间内 key 最小的键值对。
+func (v *MapView[K, V]) PopFirst() (Entry[K, V], bool) {
+	e, ok := v.first()
+	return v.m.pop(e, ok)
+}
+
+// PopLast 删除并返回区间内 key 最大的键值对。
+func (v *MapView[K, V]) PopLast() (Entry[K, V], bool) {
+	e, ok := v.last()
+	return v.m.pop(e, ok)
+}
+
+// Floor 返回区间内 key <= 给定 key 的最大键值对。
+func (v *MapView[K, V]) Floor(key K) (Entry[K, V], bool) {
+	if v.hasHi && v.m.cmp(key, v.hi) >= 0 {
+		return v.Last()
+	}
+	return entryOf(v.clamp(v.m.floor(key)))
+}
+
+// Ceiling 返回区间内 key >= 给定 key 的最小键值对。
+func (v *MapView[K, V]) Ceiling(key K) (Entry[K, V], bool) {
+	if v.hasLo && v.m.cmp(key, v.lo) < 0 {
+		return v.First()
+	}
+	return entryOf(v.clamp(v.m.ceiling(key)))
+}
+
+// Lower 返回区间内 key < 给定 key 的最大键值对。
+func (v *MapView[K, V]) Lower(key K) (Entry[K, V], bool) {
+	if v.hasHi && v.m.cmp(key, v.hi) > 0 {
+		return v.Last()
+	}
+	return entryOf(v.clamp(v.m.tree.Predecessor(v.m.probe(key))))
+}
+
+// Higher 返回区间内 key > 给定 key 的最小键值对。
+func (v *MapView[K, V]) Higher(key K) (Entry[K, V], bool) {
+	if v.hasLo && v.m.cmp(key, v.lo) < 0 {
+		return v.First()
+	}
+	return entryOf(v.clamp(v.m.tree.Successor(v.m.probe(key))))
+}
+
+// Keys 按升序返回区间内全部 key。
+func (v *MapView[K, V]) Keys() []K {
+	keys := make([]K, 0)
+	for k := range v.All() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values 按 key 升序返回区间内全部值。
+func (v *MapView[K, V]) Values() []V {
+	values := make([]V, 0)
+	for _, val := range v.All() {
+		values = append(values, val)
+	}
+	return values
+}
+
+// All 按 key 升序迭代区间内的键值对,每步 O(log n)。
+// 迭代过程中可以删除已经访问过的 key;其余修改导致的结果未定义。
+func (v *MapView[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		e, ok := v.first()
+		for ok {
+			// 先记下 key,yield 中删除当前 key 不影响继续前进
+			key := e.Key
+			if !yield(key, e.Value) {
+				return
+			}
+			e, ok = v.clamp(v.m.tree.Successor(v.m.probe(key)))
+		}
+	}
+}
+
+// Backward 按 key 降序迭代区间内的键值对,每步 O(log n)。
+func (v *MapView[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		e, ok := v.last()
+		for ok {
+			key := e.Key
+			if !yield(key, e.Value) {
+				return
+			}
+			e, ok = v.clamp(v.m.tree.Predecessor(v.m.probe(key)))
+		}
+	}
+}
+
+// inRange 判断 key 是否落在 [lo, hi) 内。
+func (v *MapView[K, V]) inRange(key K) bool {
+	if v.hasLo && v.m.cmp(key, v.lo) < 0 {
+		return false
+	}
+	if v.hasHi && v.m.cmp(key, v.hi) >= 0 {
+		return false
+	}
+	return true
+}
+
+// clamp 过滤掉落在区间外的结果。
+func (v *MapView[K, V]) clamp(e *Entry[K, V], ok bool) (*Entry[K, V], bool) {
+	if !ok || !v.inRange(e.Key) {
+		return nil, false
+	}
+	return e, true
+}
+
+func (v *MapView[K, V]) first() (*Entry[K, V], bool) {
+	if v.hasLo {
+		return v.clamp(v.m.ceiling(v.lo))
+	}
+	return v.clamp(v.m.tree.Min())
+}
+
+func (v *MapView[K, V]) last() (*Entry[K, V], bool) {
+	if v.hasHi {
+		return v.clamp(v.m.tree.Predecessor(v.m.probe(v.hi)))
+	}
+	return v.clamp(v.m.tree.Max())
+}
@@ -0,0 +1,330 @@
+package dag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leoheung/go-patterns/container/tree/dbtree"
+	"github.com/leoheung/go-patterns/parallel/pool"
+	"github.com/leoheung/go-patterns/utils"
+)
+
+// Graph 是带依赖关系的任务图:每个节点有唯一的名字、一个 func(ctx) error 任务体和若干依赖。
+// Run 在有界的 pool.WorkerPool 上执行,节点的依赖全部成功后立即开始,
+// 互不依赖的分支并发推进。
+//
+// Graph 在 Run 期间不可修改;同一个 Graph 可以多次 Run,每次 Run 相互独立。
+type Graph struct {
+	nodes map[string]*node
+	names []string // 按添加顺序
+}
+
+type node struct {
+	name string
+	fn   pool.TaskWithCtx
+	deps []string
+}
+
+// New 创建空的任务图。
+func New() *Graph {
+	return &Graph{nodes: make(map[string]*node)}
+}
+
+// Add 添加节点 name,它依赖 deps 中的节点;依赖可以在之后再添加,Validate / Run 时统一校验。
+// 名字为空、重复或 fn 为 nil 时返回错误。
+func (g *Graph) Add(name string, fn pool.TaskWithCtx, deps ...string) error {
+	if name == "" {
+		return fmt.Errorf("failed to add node: name is empty")
+	}
+	if fn == nil {
+		return fmt.Errorf("failed to add node %q: fn is nil", name)
+	}
+	if _, ok := g.nodes[name]; ok {
+		return fmt.Errorf("failed to add node %q: duplicate name", name)
+	}
+	g.nodes[name] = &node{name: name, fn: fn, deps: append([]string(nil), deps...)}
+	g.names = append(g.names, name)
+	return nil
+}
+
+// Len 返回节点数。
+func (g *Graph) Len() int {
+	return len(g.names)
+}
+
+// Validate 校验依赖都已声明且不存在环;有环时返回 *dbtree.CycleError,其中包含完整的环路径。
+func (g *Graph) Validate() error {
+	_, err := g.Order()
+	return err
+}
+
+// Order 返回一个满足依赖关系的串行执行顺序。
+func (g *Graph) Order() ([]string, error) {
+	if err := g.checkDeps(); err != nil {
+		return nil, err
+	}
+	return dbtree.BuildDependencyOrder(g.names, g.dependencies())
+}
+
+// Levels 返回分层结果:同一层的节点互不依赖。
+func (g *Graph) Levels() ([][]string, error) {
+	if err := g.checkDeps(); err != nil {
+		return nil, err
+	}
+	return dbtree.BuildDependencyLevels(g.names, g.dependencies())
+}
+
+func (g *Graph) checkDeps() error {
+	for _, name := range g.names {
+		for _, dep := range g.nodes[name].deps {
+			if _, ok := g.nodes[dep]; !ok {
+				return fmt.Errorf("node %q depends on unknown node %q", name, dep)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Graph) dependencies() map[string][]string {
+	deps := make(map[string][]string, len(g.nodes))
+	for name, n := range g.nodes {
+		deps[name] = n.deps
+	}
+	return deps
+}
+
+// FailurePolicy 决定节点失败后如何处理其余节点。
+type FailurePolicy int
+
+const (
+	// FailFast 任一节点失败后取消正在运行节点的 ctx,不再启动新节点。
+	FailFast FailurePolicy = iota
+	// ContinueOnError 只跳过(直接或间接)依赖失败节点的节点,其余分支继续执行。
+	ContinueOnError
+)
+
+// Status 节点的最终状态。
+type Status int
+
+const (
+	Succeeded Status = iota
+	Failed
+	Skipped  // 依赖失败,未执行
+	Canceled // 因 FailFast 或外部 ctx 取消而未执行或被中止
+)
+
+func (s Status) String() string {
+	switch s {
+	case Succeeded:
+		return "succeeded"
+	case Failed:
+		return "failed"
+	case Skipped:
+		return "skipped"
+	case Canceled:
+		return "canceled"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
+// NodeResult 单个节点的执行结果;未执行的节点 Start / End 为零值。
+type NodeResult struct {
+	Name     string
+	Status   Status
+	Err      error // Failed 时为任务返回的错误(panic 被转换为错误);Skipped 时说明被哪个依赖阻断
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+}
+
+// Report 一次 Run 的完整结果。
+type Report struct {
+	Results  map[string]*NodeResult
+	Finished []string // 实际执行过的节点,按完成先后排列
+	Elapsed  time.Duration
+	names    []string // 节点添加顺序
+}
+
+// ByStatus 按节点添加顺序返回处于 status 的节点名。
+func (r *Report) ByStatus(status Status) []string {
+	names := make([]string, 0)
+	for _, name := range r.names {
+		if res, ok := r.Results[name]; ok && res.Status == status {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// completion 是 worker 回报给调度循环的消息。
+type completion struct {
+	name       string
+	err        error
+	start, end time.Time
+}
+
+// Run 在最多 workers 个并发 worker 上执行整张图,返回每个节点的结果与耗时。
+//
+// 图不合法(未知依赖 / 有环)时不执行任何节点,直接返回错误。
+// 否则总会返回 Report;全部节点成功时 error 为 nil,
+// 有节点失败时 error 汇总了各失败节点的错误,外部 ctx 被取消时还包含 ctx.Err()。
+func (g *Graph) Run(ctx context.Context, workers int, policy FailurePolicy) (*Report, error) {
+	if workers <= 0 {
+		return nil, fmt.Errorf("workers <= 0 : %d", workers)
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	begin := time.Now()
+	report := &Report{
+		Results: make(map[string]*NodeResult, len(g.names)),
+		names:   append([]string(nil), g.names...),
+	}
+
+	pending := make(map[string]int, len(g.names))         // 尚未成功的依赖数
+	dependents := make(map[string][]string, len(g.names)) // 反向边
+	ready := make([]string, 0)
+	for _, name := range g.names {
+		n := g.nodes[name]
+		pending[name] = len(n.deps)
+		for _, dep := range n.deps {
+			dependents[dep] = append(dependents[dep], name)
+		}
+		if len(n.deps) == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	// 缓冲足够大,worker 回报永不阻塞
+	done := make(chan completion, len(g.names))
+	wp := pool.NewWorkerPool(workers)
+	running := 0
+	stopping := false
+	var errs []error
+
+	// skip 把 name 及其全部下游标记为 status(已有结果的节点不受影响)
+	var skip func(name string, status Status, cause error)
+	skip = func(name string, status Status, cause error) {
+		if _, ok := report.Results[name]; ok {
+			return
+		}
+		report.Results[name] = &NodeResult{Name: name, Status: status, Err: cause}
+		for _, d := range dependents[name] {
+			skip(d, status, cause)
+		}
+	}
+
+	// complete 记录一个节点的完成并推进其下游
+	complete := func(c completion) {
+		running--
+		report.Finished = append(report.Finished, c.name)
+		res := &NodeResult{Name: c.name, Start: c.start, End: c.end, Duration: c.end.Sub(c.start)}
+		report.Results[c.name] = res
+
+		if c.err != nil && runCtx.Err() != nil && isContextErr(c.err) {
+			// 因取消而中止的节点不计为失败,其下游同样记为取消
+			res.Status = Canceled
+			res.Err = c.err
+			cause := fmt.Errorf("dependency %q canceled", c.name)
+			for _, d := range dependents[c.name] {
+				skip(d, Canceled, cause)
+			}
+			return
+		}
+		if c.err != nil {
+			res.Status = Failed
+			res.Err = c.err
+			errs = append(errs, fmt.Errorf("node %q: %w", c.name, c.err))
+			cause := fmt.Errorf("dependency %q failed", c.name)
+			for _, d := range dependents[c.name] {
+				skip(d, Skipped, cause)
+			}
+			if policy == FailFast && !stopping {
+				stopping = true
+				cancel()
+			}
+			return
+		}
+
+		res.Status = Succeeded
+		for _, d := range dependents[c.name] {
+			pending[d]--
+			if pending[d] == 0 {
+				if _, decided := report.Results[d]; !decided {
+					ready = append(ready, d)
+				}
+			}
+		}
+	}
+
+	// 每轮最多提交一个节点:先处理已到达的完成消息,再检查 stopping / ctx,
+	// 保证 FailFast 下失败之后不会再有新节点以未取消的 ctx 启动。
+	for {
+		select {
+		case c := <-done:
+			complete(c)
+			continue
+		default:
+		}
+
+		if runCtx.Err() != nil {
+			// 外部 ctx 被取消
+			stopping = true
+		}
+
+		if !stopping && len(ready) > 0 && running < workers {
+			// running < workers 时最多只需等待刚回报的 worker 归还名额,Submit 不会长时间阻塞
+			name := ready[0]
+			ready = ready[1:]
+			running++
+			wp.Submit(g.task(runCtx, name, done), nil)
+			continue
+		}
+		if running == 0 {
+			break
+		}
+		complete(<-done)
+	}
+
+	// 剩余未决节点:FailFast 或外部取消导致未能启动
+	for _, name := range g.names {
+		if _, ok := report.Results[name]; !ok {
+			report.Results[name] = &NodeResult{Name: name, Status: Canceled, Err: context.Canceled}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	report.Elapsed = time.Since(begin)
+	return report, errors.Join(errs...)
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// task 包装节点任务体:记录起止时间,把 panic 转换为错误,并保证一定向 done 回报一次。
+// 返回给 WorkerPool 的错误恒为 nil,失败由调度循环统一记录。
+func (g *Graph) task(ctx context.Context, name string, done chan<- completion) pool.Task {
+	fn := g.nodes[name].fn
+	return func() error {
+		c := completion{name: name, start: time.Now()}
+		defer func() {
+			if r := recover(); r != nil {
+				c.err = fmt.Errorf("panic: %v", r)
+				utils.LogMessage(fmt.Sprintf("panic when executing node %q: %v", name, r))
+			}
+			c.end = time.Now()
+			done <- c
+		}()
+		c.err = fn(ctx)
+		return nil
+	}
+}
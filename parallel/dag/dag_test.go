@@ -0,0 +1,194 @@
+package dag
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errBoom = errors.New("boom")
+
+func ok(context.Context) error { return nil }
+
+func fail(context.Context) error { return errBoom }
+
+// checkStatus 要求 names 中的每个节点都处于 want
+func checkStatus(t *testing.T, r *Report, want Status, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if got := r.Results[name].Status; got != want {
+			t.Fatalf("node %q status = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// TestRunFailFastSingleWorker 单 worker 下 a 失败后,其余无依赖节点不得再以未取消的 ctx 执行
+func TestRunFailFastSingleWorker(t *testing.T) {
+	g := New()
+	var liveStarts atomic.Int32
+	guarded := func(ctx context.Context) error {
+		if ctx.Err() == nil {
+			liveStarts.Add(1)
+		}
+		return ctx.Err()
+	}
+	g.Add("a", fail)
+	g.Add("b", guarded)
+	g.Add("c", guarded)
+	g.Add("d", guarded)
+
+	r, err := g.Run(context.Background(), 1, FailFast)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+	if n := liveStarts.Load(); n != 0 {
+		t.Fatalf("%d nodes started with a live ctx after the failure", n)
+	}
+	checkStatus(t, r, Failed, "a")
+	checkStatus(t, r, Canceled, "b", "c", "d")
+}
+
+// TestRunFailFastCancelsRunning 失败节点会取消正在运行的并行分支
+func TestRunFailFastCancelsRunning(t *testing.T) {
+	g := New()
+	started := make(chan struct{})
+	g.Add("slow", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Add("a", func(context.Context) error {
+		<-started
+		return errBoom
+	})
+	g.Add("after", ok, "slow")
+
+	r, err := g.Run(context.Background(), 2, FailFast)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+	checkStatus(t, r, Failed, "a")
+	checkStatus(t, r, Canceled, "slow", "after")
+}
+
+// TestRunContinueOnError 只跳过失败节点的下游,无关分支照常完成
+func TestRunContinueOnError(t *testing.T) {
+	g := New()
+	g.Add("a", fail)
+	g.Add("b", ok, "a")
+	g.Add("c", ok, "b")
+	g.Add("x", ok)
+	g.Add("y", ok, "x")
+
+	r, err := g.Run(context.Background(), 2, ContinueOnError)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+	checkStatus(t, r, Failed, "a")
+	checkStatus(t, r, Skipped, "b", "c")
+	checkStatus(t, r, Succeeded, "x", "y")
+	if got := r.ByStatus(Succeeded); !slices.Equal(got, []string{"x", "y"}) {
+		t.Fatalf("ByStatus(Succeeded) = %v", got)
+	}
+}
+
+// TestRunOrder 依赖全部成功后下游才开始
+func TestRunOrder(t *testing.T) {
+	g := New()
+	g.Add("a", ok)
+	g.Add("b", ok)
+	g.Add("c", ok, "a", "b")
+	g.Add("d", ok, "c")
+
+	r, err := g.Run(context.Background(), 4, FailFast)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	checkStatus(t, r, Succeeded, "a", "b", "c", "d")
+	for _, name := range []string{"a", "b"} {
+		if r.Results[name].End.After(r.Results["c"].Start) {
+			t.Fatalf("c started before %q finished", name)
+		}
+	}
+	if r.Results["c"].End.After(r.Results["d"].Start) {
+		t.Fatal("d started before c finished")
+	}
+}
+
+// TestRunExternalCancel 外部 ctx 取消后,运行中的节点被中止,未启动的节点记为取消
+func TestRunExternalCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := New()
+	g.Add("a", func(ctx context.Context) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Add("b", ok, "a")
+	g.Add("c", ok)
+
+	r, err := g.Run(ctx, 1, ContinueOnError)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want %v", err, context.Canceled)
+	}
+	if errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, canceled nodes must not count as failures", err)
+	}
+	checkStatus(t, r, Canceled, "a", "b", "c")
+}
+
+// TestRunPanicRecovery panic 被转换为节点错误,不影响调度循环
+func TestRunPanicRecovery(t *testing.T) {
+	g := New()
+	g.Add("a", func(context.Context) error { panic("kaboom") })
+	g.Add("b", ok, "a")
+	g.Add("c", ok)
+
+	done := make(chan struct{})
+	var (
+		r   *Report
+		err error
+	)
+	go func() {
+		defer close(done)
+		r, err = g.Run(context.Background(), 2, ContinueOnError)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after a panicking node")
+	}
+	if err == nil {
+		t.Fatal("err = nil, want panic error")
+	}
+	checkStatus(t, r, Failed, "a")
+	checkStatus(t, r, Skipped, "b")
+	checkStatus(t, r, Succeeded, "c")
+}
+
+// TestRunInvalidGraph 未知依赖或有环时不执行任何节点
+func TestRunInvalidGraph(t *testing.T) {
+	var ran atomic.Bool
+	mark := func(context.Context) error {
+		ran.Store(true)
+		return nil
+	}
+	g := New()
+	g.Add("a", mark, "b")
+	g.Add("b", mark, "a")
+	if _, err := g.Run(context.Background(), 2, FailFast); err == nil {
+		t.Fatal("cycle: err = nil")
+	}
+
+	g = New()
+	g.Add("a", mark, "missing")
+	if _, err := g.Run(context.Background(), 2, FailFast); err == nil {
+		t.Fatal("unknown dependency: err = nil")
+	}
+	if ran.Load() {
+		t.Fatal("node executed on an invalid graph")
+	}
+}